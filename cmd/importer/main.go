@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"music-library/internal/config"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+	"music-library/internal/service"
+)
+
+func main() {
+	configFlags := config.AddFlags(flag.CommandLine)
+	file := flag.String("file", "", "путь к файлу импорта (CSV, JSON или NDJSON)")
+	format := flag.String("format", "", "формат файла; по умолчанию определяется по расширению")
+	batchSize := flag.Int("batch", 500, "количество песен в одной транзакции")
+	enrich := flag.Bool("enrich", false, "поставить песни без текста или ссылки в очередь обогащения")
+	dryRun := flag.Bool("dry-run", false, "только проверить файл, не сохраняя песни")
+	actor := flag.String("actor", "cli:importer", "инициатор изменений для журнала аудита")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(configFlags)
+	if err != nil {
+		log.Fatalf("Cannot load config:\n%v", err)
+	}
+
+	// Ctrl+C прерывает работу, не дожидаясь обработки оставшихся данных
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := repository.InitPostgresDB(cfg)
+	if err != nil {
+		log.Fatalf("Cannot connect to database: %v", err)
+	}
+	defer db.Close()
+
+	songRepo := repository.NewSongRepository(db)
+	importService := service.NewImportService(songRepo)
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("Cannot open import file: %v", err)
+	}
+	defer f.Close()
+
+	if *format == "" {
+		*format = service.DetectImportFormat(*file)
+	}
+
+	opts := models.ImportOptions{
+		Format:    *format,
+		BatchSize: *batchSize,
+		Enrich:    *enrich,
+		DryRun:    *dryRun,
+	}
+
+	report, err := importService.Import(ctx, f, opts, models.AuditMeta{Actor: *actor})
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("Cannot write report: %v", err)
+	}
+}
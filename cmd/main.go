@@ -1,28 +1,63 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
 	"music-library/internal/config"
 	"music-library/internal/handlers"
+	"music-library/internal/metrics"
+	"music-library/internal/middleware"
 	"music-library/internal/repository"
 	"music-library/internal/service"
+	"music-library/migrations"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 )
 
+// version версия сборки; задается при сборке через -ldflags "-X main.version=..."
+var version = "dev"
+
 func main() {
-	// Загрузка переменных окружения
-	if err := godotenv.Load(); err != nil {
-		log.Fatal("Error loading .env file")
+	// Флаги переопределяют файл конфигурации и переменные окружения
+	configFlags := config.AddFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [config print | migrate]\n", os.Args[0])
+		flag.PrintDefaults()
 	}
+	flag.Parse()
 
 	// Инициализация конфигурации
-	cfg, err := config.LoadConfig()
+	cfg, err := config.Load(configFlags)
 	if err != nil {
-		log.Fatalf("Cannot load config: %v", err)
+		log.Fatalf("Cannot load config:\n%v", err)
+	}
+
+	// Команда "config print" выводит итоговую конфигурацию без секретов,
+	// "migrate" применяет недостающие миграции схемы и завершается
+	migrateOnly := false
+	switch args := flag.Args(); {
+	case len(args) == 0:
+	case len(args) == 2 && args[0] == "config" && args[1] == "print":
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	case len(args) == 1 && args[0] == "migrate":
+		migrateOnly = true
+	default:
+		flag.Usage()
+		os.Exit(2)
 	}
 
+	gin.SetMode(cfg.LogMode)
+
 	// Подключение к базе данных
 	db, err := repository.InitPostgresDB(cfg)
 	if err != nil {
@@ -30,27 +65,160 @@ func main() {
 	}
 	defer db.Close()
 
+	metrics.RegisterDB(db, cfg.DBName)
+
+	migrationRepo := repository.NewMigrationRepository(db, migrations.Files)
+	if migrateOnly {
+		applied, err := migrationRepo.Apply(context.Background())
+		if err != nil {
+			db.Close()
+			log.Fatalf("Migration failed: %v", err)
+		}
+		log.Printf("Applied %d migrations", len(applied))
+		return
+	}
+
 	// Создание репозитория, сервисов и обработчиков
 	songRepo := repository.NewSongRepository(db)
-	externalAPI := service.NewExternalAPIService(cfg)
-	songService := service.NewSongService(songRepo, externalAPI)
+	detailsCacheRepo := repository.NewDetailsCacheRepository(db)
+	detailsCache, err := service.NewDetailsCache(cfg, detailsCacheRepo)
+	if err != nil {
+		log.Fatalf("Cannot configure details cache: %v", err)
+	}
+	externalAPI, err := service.NewExternalAPIService(cfg, detailsCache)
+	if err != nil {
+		log.Fatalf("Cannot configure external API client: %v", err)
+	}
+	songService := service.NewSongService(songRepo, cfg)
 	songHandler := handlers.NewSongHandler(songService)
+	importService := service.NewImportService(songRepo)
+	importHandler := handlers.NewImportHandler(importService)
+	exportService := service.NewExportService(songRepo)
+	exportHandler := handlers.NewExportHandler(exportService)
+	duplicateService := service.NewDuplicateService(songRepo)
+	duplicateHandler := handlers.NewDuplicateHandler(duplicateService)
+	enrichmentRepo := repository.NewEnrichmentRepository(db)
+	providerChain, err := service.NewProviderChainFromConfig(cfg, externalAPI)
+	if err != nil {
+		log.Fatalf("Cannot configure enrichment providers: %v", err)
+	}
+	enrichmentService := service.NewEnrichmentService(enrichmentRepo, providerChain, cfg)
+	enrichmentHandler := handlers.NewEnrichmentHandler(enrichmentService)
+	cacheHandler := handlers.NewCacheHandler(service.NewCacheService(detailsCache))
+	auditRepo := repository.NewAuditRepository(db)
+	auditService := service.NewAuditService(auditRepo)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	healthHandler := handlers.NewHealthHandler(service.NewHealthService(db, migrationRepo, externalAPI, version))
+
+	// SIGINT и SIGTERM запускают плавную остановку сервера
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Запуск воркеров очереди обогащения, планировщика обновления метаданных и очистки кэша;
+	// они останавливаются отдельно, после прекращения приема запросов
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	enrichmentService.Start(workersCtx)
+	refreshScheduler := service.NewRefreshScheduler(enrichmentRepo, cfg)
+	refreshScheduler.Start(workersCtx)
+	cacheSweeper := service.NewCacheSweeper(detailsCacheRepo, cfg)
+	cacheSweeper.Start(workersCtx)
 
 	// Настройка роутера Gin
-	router := gin.Default()
+	routeTimeouts, err := config.ParseRouteTimeouts(cfg.RouteTimeouts)
+	if err != nil {
+		log.Fatalf("Cannot parse route timeouts: %v", err)
+	}
 
-	// Группировка маршрутов
-	v1 := router.Group("/api")
-	{
-		v1.GET("/songs", songHandler.GetSongs)
-		v1.GET("/song/text", songHandler.GetSongText)
-		v1.POST("/song", songHandler.CreateSong)
-		v1.PUT("/song/:id", songHandler.UpdateSong)
-		v1.DELETE("/song/:id", songHandler.DeleteSong)
+	var chain []gin.HandlerFunc
+	if cfg.LogRequests {
+		chain = append(chain, gin.Logger())
+	}
+	chain = append(chain,
+		middleware.Metrics(),
+		gin.Recovery(),
+		middleware.RequestID(),
+		middleware.Deadline(time.Duration(cfg.RequestTimeout)*time.Second, routeTimeouts),
+	)
+
+	router, _, err := handlers.NewRouter(handlers.Handlers{
+		Songs:      songHandler,
+		Import:     importHandler,
+		Export:     exportHandler,
+		Duplicates: duplicateHandler,
+		Enrichment: enrichmentHandler,
+		Cache:      cacheHandler,
+		Audit:      auditHandler,
+		Health:     healthHandler,
+		Config:     cfg,
+	}, chain...)
+	if err != nil {
+		log.Fatalf("Cannot configure router: %v", err)
 	}
 
 	// Запуск сервера
-	if err := router.Run(":8080"); err != nil {
-		log.Fatalf("Failed to run server: %v", err)
+	server := &http.Server{
+		Addr:              cfg.ServerAddr,
+		Handler:           router,
+		ReadHeaderTimeout: time.Duration(cfg.ServerReadHeaderTimeout) * time.Second,
+		ReadTimeout:       time.Duration(cfg.ServerReadTimeout) * time.Second,
+		WriteTimeout:      time.Duration(cfg.ServerWriteTimeout) * time.Second,
+		IdleTimeout:       time.Duration(cfg.ServerIdleTimeout) * time.Second,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Listening on %s", cfg.ServerAddr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	var runErr error
+	select {
+	case runErr = <-serverErr:
+		log.Printf("Failed to run server: %v", runErr)
+	case <-ctx.Done():
+		log.Println("Shutting down")
+	}
+	stop()
+
+	// Остановка укладывается в общий срок: сначала сервер перестает принимать соединения
+	// и дожидается текущих запросов, затем останавливаются фоновые задачи
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeout)*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error draining HTTP requests: %v", err)
+		server.Close()
+	}
+
+	stopWorkers()
+	if err := waitAll(shutdownCtx, enrichmentService.Wait, refreshScheduler.Wait, cacheSweeper.Wait); err != nil {
+		log.Printf("Background workers did not stop in time: %v", err)
+	}
+
+	if runErr != nil {
+		db.Close()
+		os.Exit(1)
+	}
+
+	// Пул соединений закрывается отложенным db.Close
+	log.Println("Server stopped")
+}
+
+// waitAll ожидает завершения всех функций ожидания, но не дольше срока ctx
+func waitAll(ctx context.Context, waits ...func()) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, wait := range waits {
+			wait()
+		}
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func main() {
+	addr := flag.String("addr", ":8081", "адрес, на котором слушает mock-сервер")
+	fixtures := flag.String("fixtures", "cmd/mockapi/fixtures.json", "файл с фикстурами песен (JSON-массив)")
+	latency := flag.Duration("latency", 0, "задержка перед каждым ответом")
+	jitter := flag.Duration("jitter", 0, "случайная добавка к задержке в пределах [0, jitter)")
+	errorRate := flag.Float64("error-rate", 0, "доля запросов (0..1), завершающихся ошибкой")
+	errorStatuses := flag.String("error-statuses", "500,502,503", "коды ответа для случайных ошибок через запятую")
+	seed := flag.Int64("seed", 0, "начальное значение генератора случайных чисел (0 — текущее время)")
+	flag.Parse()
+
+	if *errorRate < 0 || *errorRate > 1 {
+		log.Fatalf("Invalid -error-rate %v: must be between 0 and 1", *errorRate)
+	}
+
+	statuses, err := parseStatuses(*errorStatuses)
+	if err != nil {
+		log.Fatalf("Invalid -error-statuses: %v", err)
+	}
+
+	songs, err := loadFixtures(*fixtures)
+	if err != nil {
+		log.Fatalf("Cannot load fixtures: %v", err)
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+
+	server := &mockServer{
+		songs:         songs,
+		latency:       *latency,
+		jitter:        *jitter,
+		errorRate:     *errorRate,
+		errorStatuses: statuses,
+		rnd:           rand.New(rand.NewSource(*seed)),
+	}
+
+	log.Printf("Mock music info API with %d songs listening on %s", len(songs), *addr)
+	if err := http.ListenAndServe(*addr, server.routes()); err != nil {
+		log.Fatalf("Mock server stopped: %v", err)
+	}
+}
+
+// parseStatuses разбирает список HTTP-кодов через запятую
+func parseStatuses(value string) ([]int, error) {
+	var statuses []int
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		status, err := strconv.Atoi(part)
+		if err != nil || status < 100 || status > 599 {
+			return nil, fmt.Errorf("invalid HTTP status %q", part)
+		}
+		statuses = append(statuses, status)
+	}
+
+	if len(statuses) == 0 {
+		statuses = []int{http.StatusInternalServerError}
+	}
+	return statuses, nil
+}
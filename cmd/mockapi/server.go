@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"music-library/internal/models"
+)
+
+// fixture песня из файла фикстур; Status и LatencyMS позволяют задать для конкретной
+// песни постоянную ошибку или задержку, чтобы воспроизводить сбои детерминированно
+type fixture struct {
+	models.Song
+	Status    int `json:"status,omitempty"`
+	LatencyMS int `json:"latency_ms,omitempty"`
+}
+
+// mockServer имитирует внешний API с информацией о песнях
+type mockServer struct {
+	songs         map[string]fixture
+	latency       time.Duration
+	jitter        time.Duration
+	errorRate     float64
+	errorStatuses []int
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// fixtureKey нормализует пару исполнитель/название для поиска без учета регистра
+func fixtureKey(group, song string) string {
+	return strings.ToLower(strings.TrimSpace(group)) + "\x00" + strings.ToLower(strings.TrimSpace(song))
+}
+
+// loadFixtures читает фикстуры из JSON-массива
+func loadFixtures(path string) (map[string]fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []fixture
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	songs := make(map[string]fixture, len(items))
+	for i, item := range items {
+		if item.Group == "" || item.SongName == "" {
+			return nil, fmt.Errorf("fixture %d: group and song are required", i+1)
+		}
+		songs[fixtureKey(item.Group, item.SongName)] = item
+	}
+
+	return songs, nil
+}
+
+func (s *mockServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/info", s.handleInfo)
+	return mux
+}
+
+// handleInfo реализует контракт GET /info?group=&song=, который использует ExternalAPIService
+func (s *mockServer) handleInfo(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	status := s.serveInfo(w, r)
+	log.Printf("%s %s -> %d (%s)", r.Method, r.URL.RequestURI(), status, time.Since(start).Round(time.Millisecond))
+}
+
+func (s *mockServer) serveInfo(w http.ResponseWriter, r *http.Request) int {
+	if r.Method != http.MethodGet {
+		return writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+
+	group := r.URL.Query().Get("group")
+	song := r.URL.Query().Get("song")
+	if group == "" || song == "" {
+		return writeError(w, http.StatusBadRequest, "group and song are required")
+	}
+
+	item, found := s.songs[fixtureKey(group, song)]
+
+	delay := s.delay()
+	if found && item.LatencyMS > 0 {
+		delay = time.Duration(item.LatencyMS) * time.Millisecond
+	}
+	if !s.sleep(r, delay) {
+		// Клиент не дождался ответа
+		return 499
+	}
+
+	if status, failed := s.randomError(); failed {
+		return writeError(w, status, "injected failure")
+	}
+
+	if !found {
+		return writeError(w, http.StatusNotFound, "song not found")
+	}
+	if item.Status != 0 && item.Status != http.StatusOK {
+		return writeError(w, item.Status, "fixture failure")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(upstreamResponse(item.Song)); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+	return http.StatusOK
+}
+
+// upstreamResponse формирует ответ в схеме внешнего API: releaseDate в формате ДД.ММ.ГГГГ,
+// неполные даты — как есть (ГГГГ-ММ или ГГГГ)
+func upstreamResponse(song models.Song) map[string]string {
+	releaseDate := song.ReleaseDate.String()
+	if song.ReleaseDate.Precision() == models.PrecisionDay {
+		releaseDate = song.ReleaseDate.Start().Format("02.01.2006")
+	}
+
+	return map[string]string{
+		"releaseDate": releaseDate,
+		"text":        song.Text,
+		"link":        song.Link,
+	}
+}
+
+// delay возвращает задержку ответа с учетом случайного разброса
+func (s *mockServer) delay() time.Duration {
+	if s.jitter <= 0 {
+		return s.latency
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.latency + time.Duration(s.rnd.Int63n(int64(s.jitter)))
+}
+
+// sleep ждет заданное время; false означает, что клиент закрыл соединение раньше
+func (s *mockServer) sleep(r *http.Request, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// randomError с вероятностью errorRate выбирает один из настроенных кодов ошибки
+func (s *mockServer) randomError() (int, bool) {
+	if s.errorRate <= 0 {
+		return 0, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rnd.Float64() >= s.errorRate {
+		return 0, false
+	}
+	return s.errorStatuses[s.rnd.Intn(len(s.errorStatuses))], true
+}
+
+func writeError(w http.ResponseWriter, status int, message string) int {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+	return status
+}
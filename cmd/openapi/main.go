@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"music-library/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Выводит документ OpenAPI, построенный по таблице маршрутов, и завершается с ошибкой,
+// если маршруты роутера и документ расходятся. Подходит для проверки в CI:
+//
+//	go run ./cmd/openapi -o openapi.json
+func main() {
+	output := flag.String("o", "", "файл для записи документа (по умолчанию stdout)")
+	flag.Parse()
+
+	gin.SetMode(gin.ReleaseMode)
+
+	// Маршруты регистрируются без сервисов: обработчики не вызываются
+	_, doc, err := handlers.NewRouter(handlers.Handlers{})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		log.Fatalf("Cannot encode OpenAPI document: %v", err)
+	}
+	data = append(data, '\n')
+
+	if *output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		log.Fatalf("Cannot write %s: %v", *output, err)
+	}
+}
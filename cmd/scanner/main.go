@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"music-library/internal/config"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+	"music-library/internal/service"
+)
+
+func main() {
+	configFlags := config.AddFlags(flag.CommandLine)
+	dir := flag.String("dir", "", "каталог с аудиофайлами (MP3, FLAC, OGG)")
+	full := flag.Bool("full", false, "пересканировать все файлы, а не только измененные")
+	dryRun := flag.Bool("dry-run", false, "только прочитать теги, не сохраняя песни")
+	actor := flag.String("actor", "cli:scanner", "инициатор изменений для журнала аудита")
+	flag.Parse()
+
+	if *dir == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(configFlags)
+	if err != nil {
+		log.Fatalf("Cannot load config:\n%v", err)
+	}
+
+	// Ctrl+C прерывает работу, не дожидаясь обработки оставшихся данных
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := repository.InitPostgresDB(cfg)
+	if err != nil {
+		log.Fatalf("Cannot connect to database: %v", err)
+	}
+	defer db.Close()
+
+	songRepo := repository.NewSongRepository(db)
+	songService := service.NewSongService(songRepo, cfg)
+	scannerService := service.NewScannerService(songService, repository.NewScanRepository(db))
+
+	opts := models.ScanOptions{Full: *full, DryRun: *dryRun}
+	report, err := scannerService.Scan(ctx, *dir, opts, models.AuditMeta{Actor: *actor})
+	if err != nil {
+		log.Fatalf("Scan failed: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("Cannot write report: %v", err)
+	}
+}
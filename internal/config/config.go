@@ -1,41 +1,231 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
-	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
 )
 
+// Config параметры приложения. Значения собираются слоями: значения по умолчанию,
+// файл YAML или TOML, переменные окружения (в том числе из .env) и флаги командной
+// строки; каждый следующий слой переопределяет предыдущий. Тег key задает ключ в файле
+// и имя флага, env — переменную окружения, secret скрывает значение при печати
 type Config struct {
-	DBHost     string
-	DBPort     int
-	DBUser     string
-	DBPassword string
-	DBName     string
-
-	APIBaseURL string
-	APITimeout int
+	// HTTP-сервер; сроки в секундах, нулевой срок снимает ограничение
+	ServerAddr              string `key:"server.addr" env:"SERVER_ADDR" help:"адрес HTTP-сервера"`
+	ServerReadHeaderTimeout int    `key:"server.read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT" help:"срок чтения заголовков запроса в секундах"`
+	ServerReadTimeout       int    `key:"server.read_timeout" env:"SERVER_READ_TIMEOUT" help:"срок чтения запроса в секундах"`
+	ServerWriteTimeout      int    `key:"server.write_timeout" env:"SERVER_WRITE_TIMEOUT" help:"срок записи ответа в секундах"`
+	ServerIdleTimeout       int    `key:"server.idle_timeout" env:"SERVER_IDLE_TIMEOUT" help:"срок простоя keep-alive соединения в секундах"`
+	RequestTimeout          int    `key:"server.request_timeout" env:"REQUEST_TIMEOUT" help:"срок обработки запроса в секундах"`
+	RouteTimeouts           string `key:"server.route_timeouts" env:"ROUTE_TIMEOUTS" sep:";" help:"сроки отдельных маршрутов: \"METHOD /path=duration\" через точку с запятой"`
+	ShutdownTimeout         int    `key:"server.shutdown_timeout" env:"SHUTDOWN_TIMEOUT" help:"срок завершения запросов и фоновых задач при остановке в секундах"`
+
+	// База данных; время жизни соединения в секундах
+	DBHost            string `key:"db.host" env:"DB_HOST" help:"адрес PostgreSQL"`
+	DBPort            int    `key:"db.port" env:"DB_PORT" help:"порт PostgreSQL"`
+	DBUser            string `key:"db.user" env:"DB_USER" help:"пользователь базы данных"`
+	DBPassword        string `key:"db.password" env:"DB_PASSWORD" secret:"true" help:"пароль базы данных"`
+	DBName            string `key:"db.name" env:"DB_NAME" help:"имя базы данных"`
+	DBSSLMode         string `key:"db.sslmode" env:"DB_SSLMODE" help:"режим SSL: disable, require, verify-ca или verify-full"`
+	DBMaxOpenConns    int    `key:"db.max_open_conns" env:"DB_MAX_OPEN_CONNS" help:"максимум открытых соединений (0 — без ограничения)"`
+	DBMaxIdleConns    int    `key:"db.max_idle_conns" env:"DB_MAX_IDLE_CONNS" help:"максимум простаивающих соединений"`
+	DBConnMaxLifetime int    `key:"db.conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" help:"время жизни соединения в секундах (0 — без ограничения)"`
+
+	// Пагинация: размер страницы по умолчанию и наибольший допустимый
+	SongsPageSize     int `key:"pagination.songs.default" env:"SONGS_PAGE_SIZE" help:"песен на странице по умолчанию"`
+	SongsMaxPageSize  int `key:"pagination.songs.max" env:"SONGS_MAX_PAGE_SIZE" help:"наибольшее число песен на странице"`
+	VersesPageSize    int `key:"pagination.verses.default" env:"VERSES_PAGE_SIZE" help:"куплетов на странице текста по умолчанию"`
+	VersesMaxPageSize int `key:"pagination.verses.max" env:"VERSES_MAX_PAGE_SIZE" help:"наибольшее число куплетов на странице текста"`
+
+	// Журналирование
+	LogMode     string `key:"log.mode" env:"GIN_MODE" help:"режим gin: debug, release или test"`
+	LogRequests bool   `key:"log.requests" env:"LOG_REQUESTS" help:"журналировать HTTP-запросы"`
+
+	// Внешнее API: адреса (основной и зеркала через запятую), срок ответа в секундах
+	APIBaseURL string `key:"api.base_url" env:"API_BASE_URL" sep:"," help:"адрес внешнего API и зеркала через запятую"`
+	APITimeout int    `key:"api.timeout" env:"API_TIMEOUT" help:"срок ответа внешнего API в секундах"`
+
+	// Параметры повторов и выключателя внешнего API
+	APIMaxRetries       int `key:"api.max_retries" env:"API_MAX_RETRIES" help:"число повторов запроса"`
+	APIRetryBaseDelay   int `key:"api.retry_base_delay_ms" env:"API_RETRY_BASE_DELAY_MS" help:"начальная задержка повтора в миллисекундах"`
+	APIRetryMaxDelay    int `key:"api.retry_max_delay_ms" env:"API_RETRY_MAX_DELAY_MS" help:"наибольшая задержка повтора в миллисекундах"`
+	APIBreakerThreshold int `key:"api.breaker_threshold" env:"API_BREAKER_THRESHOLD" help:"число ошибок подряд до размыкания выключателя"`
+	APIBreakerCooldown  int `key:"api.breaker_cooldown" env:"API_BREAKER_COOLDOWN" help:"пауза разомкнутого выключателя в секундах"`
+
+	// Аутентификация во внешнем API и ограничение частоты запросов к каждому адресу
+	APIAuthMode   string  `key:"api.auth.mode" env:"API_AUTH_MODE" help:"аутентификация: none, header, bearer или hmac"`
+	APIAuthHeader string  `key:"api.auth.header" env:"API_AUTH_HEADER" help:"заголовок ключа для режима header"`
+	APIKey        string  `key:"api.auth.key" env:"API_KEY" secret:"true" help:"ключ внешнего API"`
+	APIHMACSecret string  `key:"api.auth.hmac_secret" env:"API_HMAC_SECRET" secret:"true" help:"секрет подписи для режима hmac"`
+	APIRateLimit  float64 `key:"api.rate_limit" env:"API_RATE_LIMIT" help:"запросов в секунду на адрес (0 — без ограничения)"`
+	APIRateBurst  int     `key:"api.rate_burst" env:"API_RATE_BURST" help:"допустимый всплеск запросов"`
+
+	// Запись и воспроизведение ответов внешнего API
+	APICassetteMode string `key:"api.cassette.mode" env:"API_CASSETTE_MODE" help:"кассеты: off, record или replay"`
+	APICassetteDir  string `key:"api.cassette.dir" env:"API_CASSETTE_DIR" help:"каталог кассет"`
+
+	// Разбор ответа внешнего API: пути к полям, форматы даты и строгость проверки
+	APIFieldReleaseDate string `key:"api.response.release_date" env:"API_FIELD_RELEASE_DATE" sep:"," help:"пути к дате релиза через запятую"`
+	APIFieldText        string `key:"api.response.text" env:"API_FIELD_TEXT" sep:"," help:"пути к тексту через запятую"`
+	APIFieldLink        string `key:"api.response.link" env:"API_FIELD_LINK" sep:"," help:"пути к ссылке через запятую"`
+	APIDateFormats      string `key:"api.response.date_formats" env:"API_DATE_FORMATS" sep:"," help:"форматы даты в нотации Go через запятую"`
+	APIStrictResponse   bool   `key:"api.response.strict" env:"API_STRICT_RESPONSE" help:"отклонять ответы с некорректными полями"`
+
+	// Параметры кэша ответов внешнего API; сроки в секундах
+	CacheBackend       string `key:"cache.backend" env:"CACHE_BACKEND" help:"кэш: none, lru, db или layered"`
+	CacheTTL           int    `key:"cache.ttl" env:"CACHE_TTL" help:"срок хранения ответа в секундах"`
+	CacheNegativeTTL   int    `key:"cache.negative_ttl" env:"CACHE_NEGATIVE_TTL" help:"срок хранения отсутствия данных в секундах"`
+	CacheSize          int    `key:"cache.size" env:"CACHE_SIZE" help:"число записей в памяти"`
+	CacheSweepInterval int    `key:"cache.sweep_interval" env:"CACHE_SWEEP_INTERVAL" help:"интервал удаления просроченных записей из базы в секундах (0 отключает)"`
+
+	// Очередь обогащения; интервалы в секундах
+	EnrichmentWorkers      int `key:"enrichment.workers" env:"ENRICHMENT_WORKERS" help:"число воркеров"`
+	EnrichmentPollInterval int `key:"enrichment.poll_interval" env:"ENRICHMENT_POLL_INTERVAL" help:"интервал опроса очереди в секундах"`
+	EnrichmentMaxAttempts  int `key:"enrichment.max_attempts" env:"ENRICHMENT_MAX_ATTEMPTS" help:"число попыток задачи"`
+	EnrichmentBackoff      int `key:"enrichment.backoff" env:"ENRICHMENT_BACKOFF" help:"пауза перед повтором задачи в секундах"`
+
+	// Источники обогащения в порядке приоритета и их параметры
+	EnrichmentProviders       string `key:"enrichment.providers" env:"ENRICHMENT_PROVIDERS" sep:"," help:"источники имя:приоритет через запятую (api, file, lyrics, musicbrainz)"`
+	EnrichmentFieldPriority   string `key:"enrichment.field_priority" env:"ENRICHMENT_FIELD_PRIORITY" sep:";" help:"порядок источников для отдельных полей"`
+	EnrichmentMetadataFile    string `key:"enrichment.metadata_file" env:"ENRICHMENT_METADATA_FILE" help:"файл метаданных"`
+	EnrichmentLyricsDir       string `key:"enrichment.lyrics_dir" env:"ENRICHMENT_LYRICS_DIR" help:"каталог с текстами песен"`
+	EnrichmentMusicBrainzDump string `key:"enrichment.musicbrainz_dump" env:"ENRICHMENT_MUSICBRAINZ_DUMP" help:"выгрузка MusicBrainz"`
+
+	// Периодическое обновление метаданных: интервал в секундах, сроки устаревания в часах,
+	// лимит песен в минуту
+	RefreshInterval        int  `key:"refresh.interval" env:"REFRESH_INTERVAL" help:"интервал обновления в секундах (0 отключает)"`
+	RefreshStaleAfter      int  `key:"refresh.stale_after" env:"REFRESH_STALE_AFTER" help:"срок устаревания метаданных в часах"`
+	RefreshIncompleteAfter int  `key:"refresh.incomplete_after" env:"REFRESH_INCOMPLETE_AFTER" help:"срок повторного поиска неполных метаданных в часах"`
+	RefreshRate            int  `key:"refresh.rate" env:"REFRESH_RATE" help:"песен в минуту"`
+	RefreshOverwrite       bool `key:"refresh.overwrite" env:"REFRESH_OVERWRITE" help:"перезаписывать заполненные поля"`
 }
 
-func LoadConfig() (*Config, error) {
-	port, err := strconv.Atoi(os.Getenv("DB_PORT"))
-	if err != nil {
-		return nil, fmt.Errorf("invalid DB_PORT: %v", err)
+// Default возвращает конфигурацию со значениями по умолчанию
+func Default() *Config {
+	return &Config{
+		ServerAddr:              ":8080",
+		ServerReadHeaderTimeout: 10,
+		ServerIdleTimeout:       120,
+		RequestTimeout:          30,
+		RouteTimeouts:           "GET /api/songs/export=0;GET /api/admin/audit/export=0;POST /api/songs/import=10m;GET /api/admin/duplicates=2m",
+		ShutdownTimeout:         30,
+
+		DBHost:            "localhost",
+		DBPort:            5432,
+		DBUser:            "postgres",
+		DBName:            "music_library",
+		DBSSLMode:         "disable",
+		DBMaxOpenConns:    25,
+		DBMaxIdleConns:    5,
+		DBConnMaxLifetime: 300,
+
+		SongsPageSize:     10,
+		SongsMaxPageSize:  100,
+		VersesPageSize:    3,
+		VersesMaxPageSize: 10,
+
+		LogMode:     "debug",
+		LogRequests: true,
+
+		APITimeout:          10,
+		APIMaxRetries:       2,
+		APIRetryBaseDelay:   200,
+		APIRetryMaxDelay:    5000,
+		APIBreakerThreshold: 5,
+		APIBreakerCooldown:  30,
+
+		APIAuthMode:   "none",
+		APIAuthHeader: "X-API-Key",
+		APIRateBurst:  1,
+
+		APICassetteMode: "off",
+		APICassetteDir:  "cassettes",
+
+		APIFieldReleaseDate: "releaseDate,release_date",
+		APIFieldText:        "text",
+		APIFieldLink:        "link",
+		APIDateFormats:      "02.01.2006,2006-01-02,2006-01-02T15:04:05Z07:00",
+
+		CacheBackend:       "lru",
+		CacheTTL:           86400,
+		CacheNegativeTTL:   3600,
+		CacheSize:          10000,
+		CacheSweepInterval: 600,
+
+		EnrichmentWorkers:      2,
+		EnrichmentPollInterval: 2,
+		EnrichmentMaxAttempts:  5,
+		EnrichmentBackoff:      10,
+		EnrichmentProviders:    "api",
+
+		RefreshInterval:        300,
+		RefreshStaleAfter:      720,
+		RefreshIncompleteAfter: 24,
+		RefreshRate:            30,
 	}
+}
 
-	timeout, err := strconv.Atoi(os.Getenv("API_TIMEOUT"))
-	if err != nil {
-		return nil, fmt.Errorf("invalid API_TIMEOUT: %v", err)
+// Load собирает конфигурацию из всех слоев и проверяет ее. Файл конфигурации задается
+// флагом -config или переменной CONFIG_FILE; флаги учитываются только явно заданные.
+// Ошибки всех слоев и проверки возвращаются вместе
+func Load(flags *Flags) (*Config, error) {
+	var errs []error
+
+	// Файл .env необязателен: в контейнере переменные задаются окружением
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		errs = append(errs, fmt.Errorf(".env: %w", err))
 	}
 
-	return &Config{
-		DBHost:     os.Getenv("DB_HOST"),
-		DBPort:     port,
-		DBUser:     os.Getenv("DB_USER"),
-		DBPassword: os.Getenv("DB_PASSWORD"),
-		DBName:     os.Getenv("DB_NAME"),
-
-		APIBaseURL: os.Getenv("API_BASE_URL"),
-		APITimeout: timeout,
-	}, nil
+	cfg := Default()
+
+	path := os.Getenv("CONFIG_FILE")
+	if flags != nil && flags.file != "" {
+		path = flags.file
+	}
+	if path != "" {
+		errs = append(errs, cfg.applyFile(path))
+	}
+
+	errs = append(errs, cfg.applyEnv())
+	if flags != nil {
+		errs = append(errs, cfg.applyFlags(flags))
+	}
+
+	// Проверка идет и после ошибок разбора, чтобы сообщить обо всех нарушениях сразу
+	errs = append(errs, cfg.Validate())
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ParseRouteTimeouts разбирает сроки выполнения отдельных маршрутов в формате
+// "GET /api/songs/export=5m;POST /api/songs/import=0"; нулевой срок снимает ограничение
+func ParseRouteTimeouts(value string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	for _, item := range strings.Split(value, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		route, raw, ok := strings.Cut(item, "=")
+		fields := strings.Fields(route)
+		if !ok || len(fields) != 2 {
+			return nil, fmt.Errorf("invalid route timeout %q: expected \"METHOD /path=duration\"", item)
+		}
+
+		timeout, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid route timeout %q: %v", item, err)
+		}
+		timeouts[strings.ToUpper(fields[0])+" "+fields[1]] = timeout
+	}
+	return timeouts, nil
 }
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted значение секрета при печати конфигурации
+const redacted = "********"
+
+// Print выводит итоговую конфигурацию в формате YAML, пригодном для файла конфигурации.
+// Секреты заменяются на звездочки, незаданные секреты остаются пустыми
+func (c *Config) Print(w io.Writer) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	values := reflect.ValueOf(c).Elem()
+
+	for _, opt := range options {
+		value := values.Field(opt.index).Interface()
+		if opt.secret && value != "" {
+			value = redacted
+		}
+
+		var node yaml.Node
+		if err := node.Encode(value); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", opt.key, err)
+		}
+		node.LineComment = opt.help
+
+		parts := strings.Split(opt.key, ".")
+		section := root
+		for _, name := range parts[:len(parts)-1] {
+			section = childSection(section, name)
+		}
+		section.Content = append(section.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: parts[len(parts)-1]}, &node)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return fmt.Errorf("failed to print config: %w", err)
+	}
+	return encoder.Close()
+}
+
+// childSection находит вложенную секцию по имени или добавляет ее в конец
+func childSection(parent *yaml.Node, name string) *yaml.Node {
+	for i := 0; i+1 < len(parent.Content); i += 2 {
+		if parent.Content[i].Value == name && parent.Content[i+1].Kind == yaml.MappingNode {
+			return parent.Content[i+1]
+		}
+	}
+	section := &yaml.Node{Kind: yaml.MappingNode}
+	parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, section)
+	return section
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// option описание параметра конфигурации, извлеченное из тегов поля Config
+type option struct {
+	key    string
+	env    string
+	help   string
+	sep    string
+	secret bool
+	index  int
+}
+
+// options параметры конфигурации в порядке объявления полей
+var options = describeOptions()
+
+// describeOptions собирает описания параметров из тегов структуры Config
+func describeOptions() []option {
+	t := reflect.TypeOf(Config{})
+	opts := make([]option, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		opts = append(opts, option{
+			key:    field.Tag.Get("key"),
+			env:    field.Tag.Get("env"),
+			help:   field.Tag.Get("help"),
+			sep:    field.Tag.Get("sep"),
+			secret: field.Tag.Get("secret") == "true",
+			index:  i,
+		})
+	}
+	return opts
+}
+
+// lookupOption находит параметр по ключу
+func lookupOption(key string) (option, bool) {
+	for _, opt := range options {
+		if opt.key == key {
+			return opt, true
+		}
+	}
+	return option{}, false
+}
+
+// set разбирает строковое значение параметра в поле нужного типа
+func (c *Config) set(opt option, raw string) error {
+	field := reflect.ValueOf(c).Elem().Field(opt.index)
+	raw = strings.TrimSpace(raw)
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		field.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// applyFile читает файл YAML или TOML (по расширению) с вложенными секциями,
+// например db.port задается как port в секции db
+func (c *Config) applyFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	values := make(map[string]any)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return fmt.Errorf("config file %s: unsupported format %q, expected .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	flat := flatten("", values)
+	var errs []error
+	for _, key := range slices.Sorted(maps.Keys(flat)) {
+		value := flat[key]
+		opt, ok := lookupOption(key)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown key %s", path, key))
+			continue
+		}
+		if err := c.set(opt, fileValue(value, opt.sep)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", path, key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// flatten раскладывает вложенные секции файла в ключи через точку
+func flatten(prefix string, values map[string]any) map[string]any {
+	flat := make(map[string]any)
+	for key, value := range values {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if section, ok := value.(map[string]any); ok {
+			for k, v := range flatten(key, section) {
+				flat[k] = v
+			}
+			continue
+		}
+		flat[key] = value
+	}
+	return flat
+}
+
+// fileValue приводит значение из файла к строке; списки склеиваются разделителем
+// параметра, поэтому в файле их можно задавать массивами
+func fileValue(value any, sep string) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []any:
+		if sep == "" {
+			sep = ","
+		}
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, sep)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// applyEnv применяет переменные окружения; пустая переменная не переопределяет значение
+func (c *Config) applyEnv() error {
+	var errs []error
+	for _, opt := range options {
+		value := os.Getenv(opt.env)
+		if value == "" {
+			continue
+		}
+		if err := c.set(opt, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", opt.env, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Flags флаги командной строки для параметров конфигурации
+type Flags struct {
+	set  *flag.FlagSet
+	file string
+}
+
+// AddFlags регистрирует в наборе флаг -config и флаг для каждого параметра;
+// имя флага совпадает с ключом параметра, например -db.port
+func AddFlags(set *flag.FlagSet) *Flags {
+	flags := &Flags{set: set}
+	set.StringVar(&flags.file, "config", "", "файл конфигурации YAML или TOML (переменная CONFIG_FILE)")
+
+	defaults := reflect.ValueOf(Default()).Elem()
+	for _, opt := range options {
+		usage := fmt.Sprintf("%s (переменная %s)", opt.help, opt.env)
+		set.String(opt.key, fmt.Sprint(defaults.Field(opt.index).Interface()), usage)
+	}
+	return flags
+}
+
+// applyFlags применяет только явно заданные флаги
+func (c *Config) applyFlags(flags *Flags) error {
+	var errs []error
+	flags.set.Visit(func(f *flag.Flag) {
+		opt, ok := lookupOption(f.Name)
+		if !ok {
+			return
+		}
+		if err := c.set(opt, f.Value.String()); err != nil {
+			errs = append(errs, fmt.Errorf("flag -%s: %w", f.Name, err))
+		}
+	})
+	return errors.Join(errs...)
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// validator накапливает нарушения, чтобы сообщить обо всех сразу
+type validator struct {
+	errs []error
+}
+
+// check фиксирует нарушение параметра key, если условие не выполнено
+func (v *validator) check(ok bool, key, format string, args ...any) {
+	if ok {
+		return
+	}
+	name := key
+	if opt, found := lookupOption(key); found {
+		name = fmt.Sprintf("%s (%s)", key, opt.env)
+	}
+	v.errs = append(v.errs, fmt.Errorf("%s: %s", name, fmt.Sprintf(format, args...)))
+}
+
+// oneOf проверяет, что значение входит в список допустимых
+func (v *validator) oneOf(value, key string, allowed ...string) {
+	v.check(slices.Contains(allowed, value), key, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+}
+
+// nonNegative проверяет, что значение не меньше нуля
+func (v *validator) nonNegative(value int, key string) {
+	v.check(value >= 0, key, "must not be negative, got %d", value)
+}
+
+// positive проверяет, что значение больше нуля
+func (v *validator) positive(value int, key string) {
+	v.check(value > 0, key, "must be positive, got %d", value)
+}
+
+// Validate проверяет согласованность параметров и возвращает все нарушения вместе
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.check(c.ServerAddr != "", "server.addr", "must not be empty")
+	v.nonNegative(c.ServerReadHeaderTimeout, "server.read_header_timeout")
+	v.nonNegative(c.ServerReadTimeout, "server.read_timeout")
+	v.nonNegative(c.ServerWriteTimeout, "server.write_timeout")
+	v.nonNegative(c.ServerIdleTimeout, "server.idle_timeout")
+	v.nonNegative(c.RequestTimeout, "server.request_timeout")
+	v.positive(c.ShutdownTimeout, "server.shutdown_timeout")
+	if _, err := ParseRouteTimeouts(c.RouteTimeouts); err != nil {
+		v.check(false, "server.route_timeouts", "%v", err)
+	}
+
+	v.check(c.DBHost != "", "db.host", "must not be empty")
+	v.check(c.DBPort > 0 && c.DBPort <= 65535, "db.port", "must be between 1 and 65535, got %d", c.DBPort)
+	v.check(c.DBUser != "", "db.user", "must not be empty")
+	v.check(c.DBName != "", "db.name", "must not be empty")
+	v.oneOf(c.DBSSLMode, "db.sslmode", "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+	v.nonNegative(c.DBMaxOpenConns, "db.max_open_conns")
+	v.nonNegative(c.DBMaxIdleConns, "db.max_idle_conns")
+	v.check(c.DBMaxOpenConns == 0 || c.DBMaxIdleConns <= c.DBMaxOpenConns, "db.max_idle_conns",
+		"must not exceed db.max_open_conns (%d), got %d", c.DBMaxOpenConns, c.DBMaxIdleConns)
+	v.nonNegative(c.DBConnMaxLifetime, "db.conn_max_lifetime")
+
+	v.positive(c.SongsPageSize, "pagination.songs.default")
+	v.check(c.SongsMaxPageSize >= c.SongsPageSize, "pagination.songs.max",
+		"must not be less than pagination.songs.default (%d), got %d", c.SongsPageSize, c.SongsMaxPageSize)
+	v.positive(c.VersesPageSize, "pagination.verses.default")
+	v.check(c.VersesMaxPageSize >= c.VersesPageSize, "pagination.verses.max",
+		"must not be less than pagination.verses.default (%d), got %d", c.VersesPageSize, c.VersesMaxPageSize)
+
+	v.oneOf(c.LogMode, "log.mode", "debug", "release", "test")
+
+	for _, item := range strings.Split(c.APIBaseURL, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		u, err := url.Parse(item)
+		v.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "api.base_url",
+			"must be an absolute http or https URL, got %q", item)
+	}
+	v.positive(c.APITimeout, "api.timeout")
+	v.nonNegative(c.APIMaxRetries, "api.max_retries")
+	v.nonNegative(c.APIRetryBaseDelay, "api.retry_base_delay_ms")
+	v.check(c.APIRetryMaxDelay >= c.APIRetryBaseDelay, "api.retry_max_delay_ms",
+		"must not be less than api.retry_base_delay_ms (%d), got %d", c.APIRetryBaseDelay, c.APIRetryMaxDelay)
+	v.positive(c.APIBreakerThreshold, "api.breaker_threshold")
+	v.nonNegative(c.APIBreakerCooldown, "api.breaker_cooldown")
+
+	v.oneOf(c.APIAuthMode, "api.auth.mode", "none", "header", "bearer", "hmac")
+	switch c.APIAuthMode {
+	case "header":
+		v.check(c.APIAuthHeader != "", "api.auth.header", "is required for header authentication")
+		v.check(c.APIKey != "", "api.auth.key", "is required for header authentication")
+	case "bearer":
+		v.check(c.APIKey != "", "api.auth.key", "is required for bearer authentication")
+	case "hmac":
+		v.check(c.APIHMACSecret != "", "api.auth.hmac_secret", "is required for HMAC authentication")
+	}
+	v.check(c.APIRateLimit >= 0, "api.rate_limit", "must not be negative, got %g", c.APIRateLimit)
+	v.positive(c.APIRateBurst, "api.rate_burst")
+
+	v.oneOf(c.APICassetteMode, "api.cassette.mode", "off", "record", "replay")
+	v.check(c.APICassetteMode == "off" || c.APICassetteDir != "", "api.cassette.dir", "is required when cassettes are enabled")
+
+	v.oneOf(c.CacheBackend, "cache.backend", "none", "lru", "db", "layered")
+	v.nonNegative(c.CacheTTL, "cache.ttl")
+	v.nonNegative(c.CacheNegativeTTL, "cache.negative_ttl")
+	v.positive(c.CacheSize, "cache.size")
+	v.nonNegative(c.CacheSweepInterval, "cache.sweep_interval")
+
+	v.positive(c.EnrichmentWorkers, "enrichment.workers")
+	v.positive(c.EnrichmentPollInterval, "enrichment.poll_interval")
+	v.positive(c.EnrichmentMaxAttempts, "enrichment.max_attempts")
+	v.positive(c.EnrichmentBackoff, "enrichment.backoff")
+
+	v.nonNegative(c.RefreshInterval, "refresh.interval")
+	v.nonNegative(c.RefreshStaleAfter, "refresh.stale_after")
+	v.nonNegative(c.RefreshIncompleteAfter, "refresh.incomplete_after")
+	v.positive(c.RefreshRate, "refresh.rate")
+
+	return errors.Join(v.errs...)
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"music-library/internal/middleware"
+	"music-library/internal/models"
+	"music-library/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ActorHeader заголовок, идентифицирующий инициатора изменения
+const ActorHeader = "X-Actor"
+
+// AuditHandler структура для обработки HTTP-запросов к журналу аудита
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+// NewAuditHandler создает новый экземпляр обработчика журнала аудита
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// auditMeta собирает сведения об инициаторе изменяющей операции
+func auditMeta(c *gin.Context) models.AuditMeta {
+	actor := c.GetHeader(ActorHeader)
+	if actor == "" {
+		actor = "anonymous"
+	}
+
+	return models.AuditMeta{
+		Source:    models.SourceManual,
+		Actor:     actor,
+		IP:        c.ClientIP(),
+		Method:    c.Request.Method,
+		Route:     c.FullPath(),
+		RequestID: middleware.GetRequestID(c),
+	}
+}
+
+// parseAuditFilter извлекает фильтры журнала аудита из параметров запроса
+func parseAuditFilter(c *gin.Context) (models.AuditFilter, error) {
+	filter := models.AuditFilter{
+		Actor:     c.Query("actor"),
+		Action:    c.Query("action"),
+		Route:     c.Query("route"),
+		RequestID: c.Query("request_id"),
+	}
+
+	if v := c.Query("song_id"); v != "" {
+		songID, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, models.InvalidField("song_id", "must be an integer").Wrap(err)
+		}
+		filter.SongID = songID
+	}
+
+	if v := c.Query("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, models.InvalidField("from", "must be an RFC 3339 timestamp").Wrap(err)
+		}
+		filter.From = from
+	}
+
+	if v := c.Query("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, models.InvalidField("to", "must be an RFC 3339 timestamp").Wrap(err)
+		}
+		filter.To = to
+	}
+
+	return filter, nil
+}
+
+// ListEntries возвращает записи журнала аудита с фильтрацией и пагинацией
+func (h *AuditHandler) ListEntries(c *gin.Context) {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		respondError(c, err, "Invalid filter")
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil {
+		limit = 50
+	}
+
+	entries, err := h.auditService.ListEntries(c.Request.Context(), filter, page, limit)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve audit log")
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// ExportEntries выгружает записи журнала аудита в формате NDJSON
+func (h *AuditHandler) ExportEntries(c *gin.Context) {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		respondError(c, err, "Invalid filter")
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="audit.ndjson"`)
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	err = h.auditService.ExportEntries(c.Request.Context(), filter, func(entry models.AuditEntry) error {
+		return encoder.Encode(entry)
+	})
+	if err != nil {
+		// Заголовки уже отправлены, поэтому ошибку можно только залогировать
+		log.Printf("Error streaming audit log: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"music-library/internal/models"
+	"music-library/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheHandler структура для обработки запросов управления кэшем внешнего API
+type CacheHandler struct {
+	cacheService *service.CacheService
+}
+
+// NewCacheHandler создает новый экземпляр обработчика кэша
+func NewCacheHandler(cacheService *service.CacheService) *CacheHandler {
+	return &CacheHandler{cacheService: cacheService}
+}
+
+// ListEntries возвращает последние записи кэша ответов внешнего API
+func (h *CacheHandler) ListEntries(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil {
+		limit = 100
+	}
+
+	entries, err := h.cacheService.ListEntries(c.Request.Context(), limit)
+	if err != nil {
+		respondError(c, err, "Failed to list cache entries")
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetEntry возвращает закэшированный ответ для группы и песни
+func (h *CacheHandler) GetEntry(c *gin.Context) {
+	entry, err := h.cacheService.GetEntry(c.Request.Context(), c.Query("group"), c.Query("song"))
+	if err != nil {
+		respondError(c, err, "Failed to read cache entry")
+		return
+	}
+
+	if entry == nil {
+		respondProblem(c, ProblemNotFound, http.StatusNotFound, translate(requestLanguage(c), "Cache entry not found"), nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// InvalidateEntry удаляет закэшированный ответ для группы и песни
+func (h *CacheHandler) InvalidateEntry(c *gin.Context) {
+	if err := h.cacheService.Invalidate(c.Request.Context(), c.Query("group"), c.Query("song")); err != nil {
+		respondError(c, err, "Failed to invalidate cache entry")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "Cache entry invalidated",
+	})
+}
+
+// Purge удаляет все записи кэша ответов внешнего API
+func (h *CacheHandler) Purge(c *gin.Context) {
+	if err := h.cacheService.Purge(c.Request.Context()); err != nil {
+		respondError(c, err, "Failed to purge cache")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{
+		Message: "Cache purged",
+	})
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"music-library/internal/models"
+	"music-library/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DuplicateHandler структура для обработки запросов поиска и слияния дубликатов
+type DuplicateHandler struct {
+	duplicateService *service.DuplicateService
+}
+
+// NewDuplicateHandler создает новый экземпляр обработчика дубликатов
+func NewDuplicateHandler(duplicateService *service.DuplicateService) *DuplicateHandler {
+	return &DuplicateHandler{duplicateService: duplicateService}
+}
+
+// FindDuplicates возвращает кластеры вероятных дубликатов для проверки
+func (h *DuplicateHandler) FindDuplicates(c *gin.Context) {
+	threshold, err := strconv.ParseFloat(c.DefaultQuery("threshold", "0.85"), 64)
+	if err != nil {
+		threshold = 0.85
+	}
+
+	opts := models.DuplicateOptions{
+		Group:     c.Query("group"),
+		Threshold: threshold,
+		Lyrics:    c.Query("lyrics") == "true",
+	}
+
+	clusters, err := h.duplicateService.FindDuplicates(c.Request.Context(), opts)
+	if err != nil {
+		respondError(c, err, "Failed to find duplicates")
+		return
+	}
+
+	c.JSON(http.StatusOK, clusters)
+}
+
+// MergeDuplicates объединяет дубликаты в выбранную песню, перенося ссылки и недостающие поля
+func (h *DuplicateHandler) MergeDuplicates(c *gin.Context) {
+	var req models.MergeSongsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	song, err := h.duplicateService.Merge(c.Request.Context(), req, auditMeta(c))
+	if err != nil {
+		respondError(c, err, "Failed to merge duplicates")
+		return
+	}
+
+	c.JSON(http.StatusOK, song)
+}
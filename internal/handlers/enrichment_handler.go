@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"music-library/internal/models"
+	"music-library/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EnrichmentHandler структура для обработки запросов к очереди обогащения
+type EnrichmentHandler struct {
+	enrichmentService *service.EnrichmentService
+}
+
+// NewEnrichmentHandler создает новый экземпляр обработчика очереди обогащения
+func NewEnrichmentHandler(enrichmentService *service.EnrichmentService) *EnrichmentHandler {
+	return &EnrichmentHandler{enrichmentService: enrichmentService}
+}
+
+// ListJobs возвращает задачи обогащения с фильтрацией по статусу
+func (h *EnrichmentHandler) ListJobs(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil {
+		limit = 20
+	}
+
+	jobs, err := h.enrichmentService.ListJobs(c.Request.Context(), c.Query("status"), page, limit)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve enrichment jobs")
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+// RetryJob возвращает проваленную задачу обогащения в очередь
+func (h *EnrichmentHandler) RetryJob(c *gin.Context) {
+	jobID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondInvalidParam(c, "id", "Invalid job ID")
+		return
+	}
+
+	if err := h.enrichmentService.RetryJob(c.Request.Context(), jobID); err != nil {
+		respondError(c, err, "Failed to retry enrichment job")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.JobActionResponse{
+		Message: "Enrichment job requeued",
+		JobID:   jobID,
+	})
+}
+
+// RetryFailed возвращает в очередь все проваленные задачи обогащения
+func (h *EnrichmentHandler) RetryFailed(c *gin.Context) {
+	count, err := h.enrichmentService.RetryFailed(c.Request.Context())
+	if err != nil {
+		respondError(c, err, "Failed to retry enrichment jobs")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.CountResponse{
+		Message: "Failed enrichment jobs requeued",
+		Count:   count,
+	})
+}
+
+// EnrichSong ставит песню в очередь обогащения; при overwrite заполненные поля заменяются новыми данными
+func (h *EnrichmentHandler) EnrichSong(c *gin.Context) {
+	songID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondInvalidParam(c, "id", "Invalid song ID")
+		return
+	}
+
+	queued, err := h.enrichmentService.EnrichSong(c.Request.Context(), songID, c.Query("overwrite") == "true")
+	if err != nil {
+		respondError(c, err, "Failed to enqueue song for enrichment")
+		return
+	}
+
+	message := "Song queued for enrichment"
+	if !queued {
+		message = "Song is already queued for enrichment"
+	}
+
+	c.JSON(http.StatusAccepted, models.SongActionResponse{
+		Message: message,
+		SongID:  songID,
+	})
+}
+
+// EnrichSongs ставит в очередь обогащения песни, подходящие под фильтр
+func (h *EnrichmentHandler) EnrichSongs(c *gin.Context) {
+	var req models.EnrichRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	count, err := h.enrichmentService.EnrichSongs(c.Request.Context(), req)
+	if err != nil {
+		respondError(c, err, "Failed to enqueue songs for enrichment")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.CountResponse{
+		Message: "Songs queued for enrichment",
+		Count:   count,
+	})
+}
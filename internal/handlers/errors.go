@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"music-library/internal/middleware"
+	"music-library/internal/models"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// StatusClientClosedRequest клиент закрыл соединение, не дождавшись ответа
+const StatusClientClosedRequest = 499
+
+// ProblemContentType тип содержимого ответов с ошибкой по RFC 7807
+const ProblemContentType = "application/problem+json"
+
+// Типы проблем; URI относительны корню сервиса
+const (
+	ProblemValidation    = "/problems/validation-error"
+	ProblemMalformed     = "/problems/malformed-request"
+	ProblemNotFound      = "/problems/not-found"
+	ProblemConflict      = "/problems/conflict"
+	ProblemBadGateway    = "/problems/bad-gateway"
+	ProblemUnavailable   = "/problems/service-unavailable"
+	ProblemTimeout       = "/problems/timeout"
+	ProblemClientClosed  = "/problems/client-closed-request"
+	ProblemInternalError = "/problems/internal-error"
+)
+
+// problemTitles краткие заголовки типов проблем; переводятся через каталог сообщений
+var problemTitles = map[string]string{
+	ProblemValidation:    "Validation failed",
+	ProblemMalformed:     "Malformed request",
+	ProblemNotFound:      "Resource not found",
+	ProblemConflict:      "Conflict with current state",
+	ProblemBadGateway:    "Invalid upstream response",
+	ProblemUnavailable:   "Upstream service unavailable",
+	ProblemTimeout:       "Request timed out",
+	ProblemClientClosed:  "Client closed request",
+	ProblemInternalError: "Internal server error",
+}
+
+// classifyError сопоставляет ошибку предметной области или контекста с типом проблемы
+// и кодом ответа
+func classifyError(err error) (string, int) {
+	switch {
+	case errors.Is(err, models.ErrValidation):
+		return ProblemValidation, http.StatusBadRequest
+	case errors.Is(err, models.ErrNotFound):
+		return ProblemNotFound, http.StatusNotFound
+	case errors.Is(err, models.ErrConflict):
+		return ProblemConflict, http.StatusConflict
+	case errors.Is(err, models.ErrUpstreamInvalid):
+		return ProblemBadGateway, http.StatusBadGateway
+	case errors.Is(err, models.ErrUpstreamUnavailable):
+		return ProblemUnavailable, http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return ProblemTimeout, http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return ProblemClientClosed, StatusClientClosedRequest
+	}
+	return ProblemInternalError, http.StatusInternalServerError
+}
+
+// respondProblem отвечает клиенту описанием проблемы на его языке
+func respondProblem(c *gin.Context, problemType string, status int, detail string, fields []models.FieldError) {
+	lang := requestLanguage(c)
+
+	problem := models.Problem{
+		Type:      problemType,
+		Title:     translate(lang, problemTitles[problemType]),
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		RequestID: middleware.GetRequestID(c),
+	}
+	for _, field := range fields {
+		field.Message = translatef(lang, field.Format, field.Args...)
+		problem.Errors = append(problem.Errors, field)
+	}
+
+	c.Header("Content-Type", ProblemContentType)
+	c.Header("Content-Language", lang)
+	c.AbortWithStatusJSON(status, problem)
+}
+
+// respondError пишет ошибку в журнал и отвечает клиенту. В detail попадает только
+// сообщение ошибки предметной области; внутренние подробности остаются в журнале
+func respondError(c *gin.Context, err error, message string) {
+	problemType, status := classifyError(err)
+	log.Printf("%s [request %s]: %v", message, middleware.GetRequestID(c), err)
+
+	lang := requestLanguage(c)
+	detail := translate(lang, message)
+
+	var fields []models.FieldError
+	var domainErr *models.DomainError
+	if errors.As(err, &domainErr) {
+		detail += ": " + translatef(lang, domainErr.Format, domainErr.Args...)
+		fields = domainErr.Fields
+	}
+
+	respondProblem(c, problemType, status, detail, fields)
+}
+
+// respondInvalidParam отвечает ошибкой валидации параметра пути или строки запроса
+func respondInvalidParam(c *gin.Context, field, message string) {
+	fields := []models.FieldError{models.NewFieldError(field, message)}
+	respondProblem(c, ProblemValidation, http.StatusBadRequest, translate(requestLanguage(c), message), fields)
+}
+
+var registerTagNames sync.Once
+
+// bindJSON разбирает тело запроса; при ошибке отвечает проблемой с перечнем полей
+// и возвращает false
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	registerTagNames.Do(func() {
+		// В ошибках валидации поля называются так же, как в JSON
+		if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+			v.RegisterTagNameFunc(func(field reflect.StructField) string {
+				name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+				if name == "-" {
+					return ""
+				}
+				return name
+			})
+		}
+	})
+
+	err := c.ShouldBindJSON(obj)
+	if err == nil {
+		return true
+	}
+
+	lang := requestLanguage(c)
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]models.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, validationFieldError(fe))
+		}
+		respondProblem(c, ProblemValidation, http.StatusBadRequest, translate(lang, "Request body has invalid fields"), fields)
+		return false
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		fields := []models.FieldError{models.NewFieldError(typeErr.Field, "must be of type %s", typeErr.Type.String())}
+		respondProblem(c, ProblemValidation, http.StatusBadRequest, translate(lang, "Request body has invalid fields"), fields)
+		return false
+	}
+
+	respondProblem(c, ProblemMalformed, http.StatusBadRequest, translate(lang, "Request body is not valid JSON"), nil)
+	return false
+}
+
+// validationFieldError описывает нарушенное правило валидации
+func validationFieldError(fe validator.FieldError) models.FieldError {
+	// Пространство имен начинается с имени структуры, которое клиенту не нужно
+	field := fe.Namespace()
+	if _, rest, ok := strings.Cut(field, "."); ok {
+		field = rest
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return models.NewFieldError(field, "is required")
+	case "min":
+		return models.NewFieldError(field, "must be at least %s", fe.Param())
+	case "max":
+		return models.NewFieldError(field, "must be at most %s", fe.Param())
+	case "oneof":
+		return models.NewFieldError(field, "must be one of: %s", fe.Param())
+	}
+	return models.NewFieldError(field, "is invalid")
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"music-library/internal/models"
+	"music-library/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHandler структура для обработки запросов выгрузки библиотеки
+type ExportHandler struct {
+	exportService *service.ExportService
+}
+
+// NewExportHandler создает новый экземпляр обработчика выгрузки
+func NewExportHandler(exportService *service.ExportService) *ExportHandler {
+	return &ExportHandler{exportService: exportService}
+}
+
+// ExportSongs потоково выгружает песни в формате CSV, JSON, NDJSON, M3U или XSPF с теми же фильтрами, что и список песен
+func (h *ExportHandler) ExportSongs(c *gin.Context) {
+	format := c.DefaultQuery("format", service.ExportFormatJSON)
+
+	contentType, extension, ok := service.ExportContentType(format)
+	if !ok {
+		respondError(c, models.InvalidField("format", "format %q is not supported", format), "Unsupported export format")
+		return
+	}
+
+	filter, err := songFilter(c)
+	if err != nil {
+		respondError(c, err, "Invalid filter")
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="songs.%s"`, extension))
+	c.Status(http.StatusOK)
+
+	if err := h.exportService.Export(c.Request.Context(), c.Writer, format, filter); err != nil {
+		// Заголовки уже отправлены, поэтому ошибку можно только залогировать
+		log.Printf("Error streaming export: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"music-library/internal/models"
+	"music-library/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler обрабатывает пробы живости и готовности
+type HealthHandler struct {
+	healthService *service.HealthService
+}
+
+// NewHealthHandler создает новый экземпляр обработчика проб
+func NewHealthHandler(healthService *service.HealthService) *HealthHandler {
+	return &HealthHandler{healthService: healthService}
+}
+
+// Liveness сообщает, что процесс жив и обслуживает запросы; зависимости не проверяются
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, models.HealthResponse{Status: models.HealthOK})
+}
+
+// Readiness проверяет зависимости и отвечает 503, пока приложение не готово к трафику
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report := h.healthService.Readiness(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status != models.HealthReady {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"music-library/internal/models"
+	"music-library/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportHandler структура для обработки запросов пакетного импорта
+type ImportHandler struct {
+	importService *service.ImportService
+}
+
+// NewImportHandler создает новый экземпляр обработчика импорта
+func NewImportHandler(importService *service.ImportService) *ImportHandler {
+	return &ImportHandler{importService: importService}
+}
+
+// ImportSongs импортирует песни из файла CSV, JSON или NDJSON (multipart-поле file или тело запроса)
+func (h *ImportHandler) ImportSongs(c *gin.Context) {
+	format := c.Query("format")
+
+	var body io.Reader = c.Request.Body
+	if file, header, err := c.Request.FormFile("file"); err == nil {
+		defer file.Close()
+		body = file
+		if format == "" {
+			format = service.DetectImportFormat(header.Filename)
+		}
+	}
+
+	if format == "" {
+		format = formatFromContentType(c.ContentType())
+	}
+
+	batchSize, err := strconv.Atoi(c.DefaultQuery("batch_size", "500"))
+	if err != nil {
+		batchSize = 500
+	}
+
+	opts := models.ImportOptions{
+		Format:    format,
+		BatchSize: batchSize,
+		Enrich:    c.Query("enrich") == "true",
+		DryRun:    c.Query("dry_run") == "true",
+	}
+
+	report, err := h.importService.Import(c.Request.Context(), body, opts, auditMeta(c))
+	if err != nil {
+		respondError(c, err, "Failed to import songs")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// formatFromContentType определяет формат импорта по типу содержимого
+func formatFromContentType(contentType string) string {
+	switch contentType {
+	case "text/csv":
+		return service.ImportFormatCSV
+	case "application/json":
+		return service.ImportFormatJSON
+	case "application/x-ndjson", "application/jsonl":
+		return service.ImportFormatNDJSON
+	}
+	return ""
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Поддерживаемые языки ответов с ошибками
+const (
+	LangEnglish = "en"
+	LangRussian = "ru"
+)
+
+// messagesRU переводы сообщений об ошибках; ключом служит английский текст
+// или строка формата, с которой создана ошибка
+var messagesRU = map[string]string{
+	// Заголовки типов проблем
+	"Validation failed":            "Ошибка валидации",
+	"Malformed request":            "Некорректный запрос",
+	"Resource not found":           "Ресурс не найден",
+	"Conflict with current state":  "Конфликт с текущим состоянием",
+	"Invalid upstream response":    "Некорректный ответ внешнего сервиса",
+	"Upstream service unavailable": "Внешний сервис недоступен",
+	"Request timed out":            "Истекло время обработки запроса",
+	"Client closed request":        "Клиент закрыл соединение",
+	"Internal server error":        "Внутренняя ошибка сервера",
+
+	// Сообщения обработчиков
+	"Request body has invalid fields":                                "Тело запроса содержит некорректные поля",
+	"Request body is not valid JSON":                                 "Тело запроса не является корректным JSON",
+	"Invalid song ID":                                                "Некорректный ID песни",
+	"Invalid job ID":                                                 "Некорректный ID задачи",
+	"Invalid filter":                                                 "Некорректный фильтр",
+	"Unsupported export format":                                      "Неподдерживаемый формат выгрузки",
+	"Cache entry not found":                                          "Запись кэша не найдена",
+	"Failed to create song":                                          "Не удалось создать песню",
+	"Failed to delete song":                                          "Не удалось удалить песню",
+	"Failed to update song":                                          "Не удалось обновить песню",
+	"Failed to update field locks":                                   "Не удалось изменить блокировку полей",
+	"Failed to retrieve songs":                                       "Не удалось получить список песен",
+	"Failed to retrieve song text":                                   "Не удалось получить текст песни",
+	"Failed to import songs":                                         "Не удалось импортировать песни",
+	"Failed to find duplicates":                                      "Не удалось найти дубликаты",
+	"Failed to merge duplicates":                                     "Не удалось объединить дубликаты",
+	"Failed to retrieve audit log":                                   "Не удалось получить журнал аудита",
+	"Failed to list cache entries":                                   "Не удалось получить записи кэша",
+	"Failed to read cache entry":                                     "Не удалось прочитать запись кэша",
+	"Failed to invalidate cache entry":                               "Не удалось удалить запись кэша",
+	"Failed to purge cache":                                          "Не удалось очистить кэш",
+	"Failed to retrieve enrichment jobs":                             "Не удалось получить задачи обогащения",
+	"Failed to retry enrichment job":                                 "Не удалось повторить задачу обогащения",
+	"Failed to retry enrichment jobs":                                "Не удалось повторить задачи обогащения",
+	"Failed to enqueue song for enrichment":                          "Не удалось поставить песню в очередь обогащения",
+	"Failed to enqueue songs for enrichment":                         "Не удалось поставить песни в очередь обогащения",
+	"must be an integer":                                             "должно быть целым числом",
+	"must be an RFC 3339 timestamp":                                  "должно быть временем в формате RFC 3339",
+	"format %q is not supported":                                     "формат %q не поддерживается",
+	"is required":                                                    "обязательное поле",
+	"is invalid":                                                     "некорректное значение",
+	"must be at least %s":                                            "должно быть не меньше %s",
+	"must be at most %s":                                             "должно быть не больше %s",
+	"must be one of: %s":                                             "должно быть одним из: %s",
+	"must be of type %s":                                             "должно иметь тип %s",
+	"must be at most %d characters":                                  "должно быть не длиннее %d символов",
+	"must be an absolute http(s) URL":                                "должно быть абсолютной ссылкой http(s)",
+	"must be a date in DD.MM.YYYY, ISO 8601, YYYY-MM or YYYY format": "должно быть датой в формате ДД.ММ.ГГГГ, ISO 8601, ГГГГ-ММ или ГГГГ",
+
+	// Ошибки предметной области
+	"invalid fields":                            "некорректные поля",
+	"song %d not found":                         "песня %d не найдена",
+	"page %d is out of range":                   "страница %d вне диапазона",
+	"failed enrichment job %d not found":        "проваленная задача обогащения %d не найдена",
+	"song already has an active enrichment job": "у песни уже есть активная задача обогащения",
+	"details cache is disabled":                 "кэш ответов внешнего API отключен",
+	"group and song name cannot be empty":       "группа и название песни не могут быть пустыми",
+	"invalid song ID":                           "некорректный ID песни",
+	"invalid song ID %d":                        "некорректный ID песни %d",
+	"invalid job ID":                            "некорректный ID задачи",
+	"duplicate IDs cannot be empty":             "список дубликатов не может быть пустым",
+	"duplicate IDs must differ from keep ID":    "дубликаты не должны совпадать с сохраняемой песней",
+	"unknown song field %q":                     "неизвестное поле песни %q",
+	"failed to read import file: %v":            "не удалось прочитать файл импорта: %v",
+	"unsupported import format %q":              "неподдерживаемый формат импорта %q",
+	"song not found in external API":            "песня не найдена во внешнем API",
+	"external API unavailable":                  "внешний API недоступен",
+	"invalid response from external API":        "некорректный ответ внешнего API",
+}
+
+// requestLanguage выбирает язык ответа по заголовку Accept-Language; по умолчанию английский
+func requestLanguage(c *gin.Context) string {
+	best, bestQ := LangEnglish, -1.0
+	for _, item := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(item), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if (base == LangEnglish || base == LangRussian) && q > bestQ {
+			best, bestQ = base, q
+		}
+	}
+	return best
+}
+
+// translate переводит сообщение; без перевода возвращается исходный английский текст
+func translate(lang, message string) string {
+	if lang == LangRussian {
+		if translated, ok := messagesRU[message]; ok {
+			return translated
+		}
+	}
+	return message
+}
+
+// translatef переводит строку формата и подставляет аргументы
+func translatef(lang, format string, args ...interface{}) string {
+	if len(args) == 0 {
+		return translate(lang, format)
+	}
+	return fmt.Sprintf(translate(lang, format), args...)
+}
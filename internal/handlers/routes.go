@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"music-library/internal/config"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"music-library/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIPrefix общий префикс маршрутов API
+const APIPrefix = "/api"
+
+// apiInfo общие сведения для документа OpenAPI
+var apiInfo = openapi.Info{
+	Title:       "Music Library API",
+	Version:     "1.0",
+	Description: "API для управления библиотекой песен",
+}
+
+// Handlers обработчики, из которых собирается API. Для построения документа
+// OpenAPI достаточно нулевого значения: обработчики при этом не вызываются
+type Handlers struct {
+	Songs      *SongHandler
+	Import     *ImportHandler
+	Export     *ExportHandler
+	Duplicates *DuplicateHandler
+	Enrichment *EnrichmentHandler
+	Cache      *CacheHandler
+	Audit      *AuditHandler
+	Health     *HealthHandler
+
+	// Config задает значения по умолчанию, попадающие в документ, например размеры
+	// страниц; без него используются значения конфигурации по умолчанию
+	Config *config.Config
+}
+
+// Route маршрут API вместе с его описанием для документа OpenAPI
+type Route struct {
+	openapi.Endpoint
+	Handler gin.HandlerFunc
+}
+
+// NewRouter регистрирует маршруты API, документ OpenAPI и Swagger UI, после чего
+// сверяет маршруты роутера с документом: незадокументированный маршрут — ошибка
+func NewRouter(h Handlers, middleware ...gin.HandlerFunc) (*gin.Engine, *openapi.Document, error) {
+	router := gin.New()
+	router.Use(middleware...)
+
+	routes := h.Routes()
+	endpoints := make([]openapi.Endpoint, 0, len(routes))
+	for _, route := range routes {
+		router.Handle(route.Method, route.Path, route.Handler)
+		endpoints = append(endpoints, route.Endpoint)
+	}
+
+	doc := openapi.NewDocument(apiInfo, endpoints)
+	docsRoutes := openapi.Mount(router, APIPrefix, doc)
+
+	if err := doc.CheckRoutes(router.Routes(), docsRoutes...); err != nil {
+		return nil, nil, fmt.Errorf("OpenAPI document does not match registered routes:\n%w", err)
+	}
+
+	return router, doc, nil
+}
+
+// Описания повторяющихся параметров
+var (
+	songIDParam = openapi.Param{Name: "id", In: openapi.InPath, Type: openapi.TypeInteger, Description: "ID песни"}
+	groupParam  = openapi.Param{Name: "group", In: openapi.InQuery, Type: openapi.TypeString, Description: "Название группы"}
+	songParam   = openapi.Param{Name: "song", In: openapi.InQuery, Type: openapi.TypeString, Description: "Название песни"}
+	actorParam  = openapi.Param{Name: "actor", In: openapi.InQuery, Type: openapi.TypeString, Description: "Инициатор изменения"}
+	actionParam = openapi.Param{Name: "action", In: openapi.InQuery, Type: openapi.TypeString, Description: "Действие",
+		Enum: []string{models.AuditActionCreate, models.AuditActionUpdate, models.AuditActionDelete, models.AuditActionMerge, models.AuditActionLock}}
+	auditSongParam = openapi.Param{Name: "song_id", In: openapi.InQuery, Type: openapi.TypeInteger, Description: "ID песни"}
+	fromParam      = openapi.Param{Name: "from", In: openapi.InQuery, Type: openapi.TypeString, Description: "Начало периода (RFC 3339)"}
+	toParam        = openapi.Param{Name: "to", In: openapi.InQuery, Type: openapi.TypeString, Description: "Конец периода (RFC 3339)"}
+
+	// Фильтры списка песен, общие для списка и выгрузки
+	songFilterParams = []openapi.Param{
+		groupParam,
+		songParam,
+		{Name: "released_from", In: openapi.InQuery, Type: openapi.TypeString,
+			Description: "Начало диапазона дат релиза (ГГГГ-ММ-ДД, ГГГГ-ММ или ГГГГ); неполная дата песни должна целиком лежать в диапазоне"},
+		{Name: "released_to", In: openapi.InQuery, Type: openapi.TypeString,
+			Description: "Конец диапазона дат релиза включительно (ГГГГ-ММ-ДД, ГГГГ-ММ или ГГГГ)"},
+		{Name: "sort", In: openapi.InQuery, Type: openapi.TypeString, Description: "Порядок сортировки", Default: models.SortByID,
+			Enum: []string{models.SortByID, models.SortByReleaseDate, models.SortByReleaseDateDesc}},
+	}
+)
+
+// pageParams параметры пагинации с лимитом по умолчанию
+func pageParams(defaultLimit int, limitDescription string) []openapi.Param {
+	return []openapi.Param{
+		{Name: "page", In: openapi.InQuery, Type: openapi.TypeInteger, Description: "Номер страницы", Default: 1},
+		{Name: "limit", In: openapi.InQuery, Type: openapi.TypeInteger, Description: limitDescription, Default: defaultLimit},
+	}
+}
+
+// jsonBody тело запроса в формате JSON
+func jsonBody(description string, model any) *openapi.Body {
+	return &openapi.Body{Description: description, Required: true, Model: model}
+}
+
+// ok успешный ответ в формате JSON
+func ok(status int, description string, model any) openapi.Reply {
+	return openapi.Reply{Status: status, Description: description, ContentTypes: []string{openapi.JSONContentType}, Model: model}
+}
+
+// problems ответы с ошибкой в формате problem+json
+func problems(statuses ...int) []openapi.Reply {
+	replies := make([]openapi.Reply, 0, len(statuses))
+	for _, status := range statuses {
+		replies = append(replies, openapi.Reply{
+			Status:       status,
+			Description:  http.StatusText(status),
+			ContentTypes: []string{ProblemContentType},
+			Model:        models.Problem{},
+		})
+	}
+	return replies
+}
+
+// replies объединяет успешный ответ с ответами об ошибках
+func replies(success openapi.Reply, failures ...int) []openapi.Reply {
+	return append([]openapi.Reply{success}, problems(failures...)...)
+}
+
+// Routes возвращает все маршруты API; по этой таблице регистрируются обработчики
+// и строится документ OpenAPI, поэтому они не могут разойтись
+func (h Handlers) Routes() []Route {
+	const (
+		songsTag  = "songs"
+		adminTag  = "admin"
+		healthTag = "health"
+	)
+
+	cfg := h.Config
+	if cfg == nil {
+		cfg = config.Default()
+	}
+
+	return []Route{
+		{Handler: h.Health.Liveness, Endpoint: openapi.Endpoint{
+			Method: http.MethodGet, Path: "/healthz", ID: "liveness", Tag: healthTag,
+			Summary:     "Проба живости",
+			Description: "Сообщает, что процесс запущен и обслуживает запросы; зависимости не проверяются",
+			Responses:   []openapi.Reply{ok(http.StatusOK, "Процесс жив", models.HealthResponse{})},
+		}},
+		{Handler: h.Health.Readiness, Endpoint: openapi.Endpoint{
+			Method: http.MethodGet, Path: "/readyz", ID: "readiness", Tag: healthTag,
+			Summary: "Проба готовности",
+			Description: "Проверяет базу данных и примененные миграции, сообщает доступность адресов внешнего API, " +
+				"состояние их выключателей и сведения о сборке",
+			Responses: []openapi.Reply{
+				ok(http.StatusOK, "Приложение готово", models.Readiness{}),
+				ok(http.StatusServiceUnavailable, "Приложение не готово", models.Readiness{}),
+			},
+		}},
+		{Handler: gin.WrapH(metrics.Handler()), Endpoint: openapi.Endpoint{
+			Method: http.MethodGet, Path: "/metrics", ID: "metrics", Tag: healthTag,
+			Summary:     "Метрики Prometheus",
+			Description: "Метрики HTTP-запросов, пула соединений и запросов к базе данных, вызовов внешнего API и доменных событий",
+			Responses: []openapi.Reply{
+				{Status: http.StatusOK, Description: "Метрики в текстовом формате Prometheus", ContentTypes: []string{"text/plain"}, Model: ""},
+			},
+		}},
+		{Handler: h.Songs.GetSongs, Endpoint: openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/songs", ID: "getSongs", Tag: songsTag,
+			Summary:     "Получение списка песен",
+			Description: "Возвращает список песен с фильтрацией и пагинацией",
+			Params:      append(append([]openapi.Param{}, songFilterParams...), pageParams(cfg.SongsPageSize, "Количество записей на странице")...),
+			Responses:   replies(ok(http.StatusOK, "Список песен", []models.Song{}), http.StatusBadRequest, http.StatusInternalServerError),
+		}},
+		{Handler: h.Export.ExportSongs, Endpoint: openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/songs/export", ID: "exportSongs", Tag: songsTag,
+			Summary:     "Выгрузка библиотеки",
+			Description: "Потоково выгружает песни в формате CSV, JSON, NDJSON, M3U или XSPF с теми же фильтрами, что и список песен",
+			Params: append([]openapi.Param{
+				{Name: "format", In: openapi.InQuery, Type: openapi.TypeString, Description: "Формат выгрузки", Default: "json",
+					Enum: []string{"csv", "json", "ndjson", "m3u", "xspf"}},
+			}, songFilterParams...),
+			Responses: replies(openapi.Reply{
+				Status:       http.StatusOK,
+				Description:  "Файл выгрузки",
+				ContentTypes: []string{"text/csv", "application/json", "application/x-ndjson", "audio/x-mpegurl", "application/xspf+xml"},
+			}, http.StatusBadRequest),
+		}},
+		{Handler: h.Import.ImportSongs, Endpoint: openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/songs/import", ID: "importSongs", Tag: songsTag,
+			Summary:     "Пакетный импорт песен",
+			Description: "Импортирует песни из файла CSV, JSON или NDJSON (multipart-поле file или тело запроса)",
+			Params: []openapi.Param{
+				{Name: "format", In: openapi.InQuery, Type: openapi.TypeString, Description: "Формат файла", Enum: []string{"csv", "json", "ndjson"}},
+				{Name: "batch_size", In: openapi.InQuery, Type: openapi.TypeInteger, Description: "Размер пачки вставки", Default: 500},
+				{Name: "enrich", In: openapi.InQuery, Type: openapi.TypeBoolean, Description: "Поставить песни без текста или ссылки в очередь обогащения", Default: false},
+				{Name: "dry_run", In: openapi.InQuery, Type: openapi.TypeBoolean, Description: "Только проверить файл, не сохраняя песни", Default: false},
+			},
+			Body: &openapi.Body{
+				Description:  "Файл импорта",
+				Required:     true,
+				ContentTypes: []string{"multipart/form-data", "text/csv", "application/json", "application/x-ndjson"},
+			},
+			Responses: replies(ok(http.StatusOK, "Итог импорта", models.ImportReport{}), http.StatusBadRequest, http.StatusInternalServerError),
+		}},
+		{Handler: h.Enrichment.EnrichSongs, Endpoint: openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/songs/enrich", ID: "enrichSongs", Tag: songsTag,
+			Summary:     "Повторное обогащение набора песен",
+			Description: "Ставит в очередь обогащения песни, подходящие под фильтр",
+			Body:        jsonBody("Фильтр песен", models.EnrichRequest{}),
+			Responses:   replies(ok(http.StatusAccepted, "Песни поставлены в очередь", models.CountResponse{}), http.StatusBadRequest, http.StatusInternalServerError),
+		}},
+		{Handler: h.Songs.GetSongText, Endpoint: openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/song/text", ID: "getSongText", Tag: songsTag,
+			Summary:     "Получение текста песни",
+			Description: "Возвращает текст песни с пагинацией по куплетам",
+			Params: append([]openapi.Param{
+				{Name: "song_id", In: openapi.InQuery, Type: openapi.TypeInteger, Description: "ID песни", Required: true},
+			}, pageParams(cfg.VersesPageSize, "Количество куплетов на странице")...),
+			Responses: replies(ok(http.StatusOK, "Страница текста", models.SongTextResponse{}), http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError),
+		}},
+		{Handler: h.Songs.CreateSong, Endpoint: openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/song", ID: "createSong", Tag: songsTag,
+			Summary:     "Добавление новой песни",
+			Description: "Создает новую запись о песне",
+			Body:        jsonBody("Информация о песне", models.CreateSongRequest{}),
+			Responses:   replies(ok(http.StatusCreated, "Созданная песня", models.Song{}), http.StatusBadRequest, http.StatusInternalServerError),
+		}},
+		{Handler: h.Songs.UpdateSong, Endpoint: openapi.Endpoint{
+			Method: http.MethodPut, Path: "/api/song/:id", ID: "updateSong", Tag: songsTag,
+			Summary:     "Обновление информации о песне",
+			Description: "Обновляет существующую запись о песне",
+			Params:      []openapi.Param{songIDParam},
+			Body:        jsonBody("Обновленная информация о песне", models.Song{}),
+			Responses:   replies(ok(http.StatusOK, "Песня обновлена", models.SongActionResponse{}), http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError),
+		}},
+		{Handler: h.Songs.DeleteSong, Endpoint: openapi.Endpoint{
+			Method: http.MethodDelete, Path: "/api/song/:id", ID: "deleteSong", Tag: songsTag,
+			Summary:     "Удаление песни",
+			Description: "Удаляет запись о песне по идентификатору",
+			Params:      []openapi.Param{songIDParam},
+			Responses:   replies(ok(http.StatusOK, "Песня удалена", models.SongActionResponse{}), http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError),
+		}},
+		{Handler: h.Songs.SetFieldLocks, Endpoint: openapi.Endpoint{
+			Method: http.MethodPut, Path: "/api/song/:id/locks", ID: "setFieldLocks", Tag: songsTag,
+			Summary:     "Блокировка полей песни",
+			Description: "Блокирует или разблокирует поля песни, чтобы обогащение их не перезаписывало",
+			Params:      []openapi.Param{songIDParam},
+			Body:        jsonBody("Поля и признак блокировки", models.FieldLocksRequest{}),
+			Responses:   replies(ok(http.StatusOK, "Песня с обновленными блокировками", models.Song{}), http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError),
+		}},
+		{Handler: h.Enrichment.EnrichSong, Endpoint: openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/song/:id/enrich", ID: "enrichSong", Tag: songsTag,
+			Summary:     "Повторное обогащение песни",
+			Description: "Ставит песню в очередь обогащения; при overwrite заполненные поля заменяются новыми данными",
+			Params: []openapi.Param{
+				songIDParam,
+				{Name: "overwrite", In: openapi.InQuery, Type: openapi.TypeBoolean, Description: "Заменять уже заполненные поля", Default: false},
+			},
+			Responses: replies(ok(http.StatusAccepted, "Песня поставлена в очередь", models.SongActionResponse{}), http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError),
+		}},
+
+		{Handler: h.Audit.ListEntries, Endpoint: openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/admin/audit", ID: "listAuditEntries", Tag: adminTag,
+			Summary:     "Просмотр журнала аудита",
+			Description: "Возвращает записи журнала аудита с фильтрацией и пагинацией",
+			Params: append([]openapi.Param{
+				actorParam, actionParam, auditSongParam,
+				{Name: "route", In: openapi.InQuery, Type: openapi.TypeString, Description: "Маршрут"},
+				{Name: "request_id", In: openapi.InQuery, Type: openapi.TypeString, Description: "ID запроса"},
+				fromParam, toParam,
+			}, pageParams(50, "Количество записей на странице")...),
+			Responses: replies(ok(http.StatusOK, "Записи журнала", []models.AuditEntry{}), http.StatusBadRequest, http.StatusInternalServerError),
+		}},
+		{Handler: h.Audit.ExportEntries, Endpoint: openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/admin/audit/export", ID: "exportAuditEntries", Tag: adminTag,
+			Summary:     "Выгрузка журнала аудита",
+			Description: "Выгружает записи журнала аудита в формате NDJSON",
+			Params:      []openapi.Param{actorParam, actionParam, auditSongParam, fromParam, toParam},
+			Responses: replies(openapi.Reply{
+				Status:       http.StatusOK,
+				Description:  "Записи журнала, по одной на строку",
+				ContentTypes: []string{"application/x-ndjson"},
+				Model:        models.AuditEntry{},
+			}, http.StatusBadRequest),
+		}},
+		{Handler: h.Duplicates.FindDuplicates, Endpoint: openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/admin/duplicates", ID: "findDuplicates", Tag: adminTag,
+			Summary:     "Поиск дубликатов",
+			Description: "Возвращает кластеры вероятных дубликатов для проверки",
+			Params: []openapi.Param{
+				{Name: "group", In: openapi.InQuery, Type: openapi.TypeString, Description: "Ограничить поиск группой"},
+				{Name: "threshold", In: openapi.InQuery, Type: openapi.TypeNumber, Description: "Порог сходства названий от 0 до 1", Default: 0.85},
+				{Name: "lyrics", In: openapi.InQuery, Type: openapi.TypeBoolean, Description: "Сравнивать также тексты песен", Default: false},
+			},
+			Responses: replies(ok(http.StatusOK, "Кластеры дубликатов", []models.DuplicateCluster{}), http.StatusBadRequest, http.StatusInternalServerError),
+		}},
+		{Handler: h.Duplicates.MergeDuplicates, Endpoint: openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/admin/duplicates/merge", ID: "mergeDuplicates", Tag: adminTag,
+			Summary:     "Слияние дубликатов",
+			Description: "Объединяет дубликаты в выбранную песню, перенося ссылки и недостающие поля",
+			Body:        jsonBody("Сохраняемая песня и дубликаты", models.MergeSongsRequest{}),
+			Responses:   replies(ok(http.StatusOK, "Сохраненная песня", models.Song{}), http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError),
+		}},
+		{Handler: h.Cache.ListEntries, Endpoint: openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/admin/cache", ID: "listCacheEntries", Tag: adminTag,
+			Summary:     "Просмотр кэша внешнего API",
+			Description: "Возвращает последние записи кэша ответов внешнего API",
+			Params: []openapi.Param{
+				{Name: "limit", In: openapi.InQuery, Type: openapi.TypeInteger, Description: "Количество записей", Default: 100},
+			},
+			Responses: replies(ok(http.StatusOK, "Записи кэша", []models.DetailsCacheEntry{}), http.StatusConflict, http.StatusInternalServerError),
+		}},
+		{Handler: h.Cache.Purge, Endpoint: openapi.Endpoint{
+			Method: http.MethodDelete, Path: "/api/admin/cache", ID: "purgeCache", Tag: adminTag,
+			Summary:     "Очистка кэша внешнего API",
+			Description: "Удаляет все записи кэша ответов внешнего API",
+			Responses:   replies(ok(http.StatusOK, "Кэш очищен", models.MessageResponse{}), http.StatusConflict, http.StatusInternalServerError),
+		}},
+		{Handler: h.Cache.GetEntry, Endpoint: openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/admin/cache/entry", ID: "getCacheEntry", Tag: adminTag,
+			Summary:     "Запись кэша внешнего API",
+			Description: "Возвращает закэшированный ответ для группы и песни",
+			Params:      []openapi.Param{requiredParam(groupParam), requiredParam(songParam)},
+			Responses:   replies(ok(http.StatusOK, "Запись кэша", models.DetailsCacheEntry{}), http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError),
+		}},
+		{Handler: h.Cache.InvalidateEntry, Endpoint: openapi.Endpoint{
+			Method: http.MethodDelete, Path: "/api/admin/cache/entry", ID: "invalidateCacheEntry", Tag: adminTag,
+			Summary:     "Удаление записи кэша внешнего API",
+			Description: "Удаляет закэшированный ответ для группы и песни",
+			Params:      []openapi.Param{requiredParam(groupParam), requiredParam(songParam)},
+			Responses:   replies(ok(http.StatusOK, "Запись удалена", models.MessageResponse{}), http.StatusConflict, http.StatusInternalServerError),
+		}},
+		{Handler: h.Enrichment.ListJobs, Endpoint: openapi.Endpoint{
+			Method: http.MethodGet, Path: "/api/admin/enrichment/jobs", ID: "listEnrichmentJobs", Tag: adminTag,
+			Summary:     "Просмотр очереди обогащения",
+			Description: "Возвращает задачи обогащения с фильтрацией по статусу",
+			Params: append([]openapi.Param{
+				{Name: "status", In: openapi.InQuery, Type: openapi.TypeString, Description: "Статус задачи",
+					Enum: []string{models.JobQueued, models.JobRunning, models.JobDone, models.JobFailed}},
+			}, pageParams(20, "Количество записей на странице")...),
+			Responses: replies(ok(http.StatusOK, "Задачи обогащения", []models.EnrichmentJob{}), http.StatusInternalServerError),
+		}},
+		{Handler: h.Enrichment.RetryJob, Endpoint: openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/admin/enrichment/jobs/:id/retry", ID: "retryEnrichmentJob", Tag: adminTag,
+			Summary:     "Повтор задачи обогащения",
+			Description: "Возвращает проваленную задачу обогащения в очередь",
+			Params: []openapi.Param{
+				{Name: "id", In: openapi.InPath, Type: openapi.TypeInteger, Description: "ID задачи"},
+			},
+			Responses: replies(ok(http.StatusOK, "Задача возвращена в очередь", models.JobActionResponse{}), http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError),
+		}},
+		{Handler: h.Enrichment.RetryFailed, Endpoint: openapi.Endpoint{
+			Method: http.MethodPost, Path: "/api/admin/enrichment/retry-failed", ID: "retryFailedEnrichmentJobs", Tag: adminTag,
+			Summary:     "Повтор всех проваленных задач обогащения",
+			Description: "Возвращает в очередь все проваленные задачи обогащения",
+			Responses:   replies(ok(http.StatusOK, "Задачи возвращены в очередь", models.CountResponse{}), http.StatusInternalServerError),
+		}},
+	}
+}
+
+// requiredParam копия описания параметра с признаком обязательности
+func requiredParam(p openapi.Param) openapi.Param {
+	p.Required = true
+	return p
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"music-library/internal/config"
+	"music-library/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// loadSpec строит роутер и загружает опубликованный им документ OpenAPI так же,
+// как его получает клиент
+func loadSpec(t *testing.T, cfg *config.Config) (*gin.Engine, *openapi.Document) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router, _, err := NewRouter(Handlers{Config: cfg})
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, APIPrefix+"/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s/openapi.json: status %d", APIPrefix, rec.Code)
+	}
+
+	var doc openapi.Document
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode OpenAPI document: %v", err)
+	}
+	return router, &doc
+}
+
+// operation возвращает операцию документа по методу и пути в синтаксисе gin
+func operation(doc *openapi.Document, method, ginPath string) *openapi.Operation {
+	item, ok := doc.Paths[openapi.PathFromGin(ginPath)]
+	if !ok {
+		return nil
+	}
+	return (*item)[strings.ToLower(method)]
+}
+
+func TestSpecMatchesRouter(t *testing.T) {
+	router, doc := loadSpec(t, nil)
+
+	registered := map[string]bool{}
+	for _, route := range router.Routes() {
+		if strings.HasPrefix(route.Path, APIPrefix+"/docs/") || route.Path == APIPrefix+"/openapi.json" {
+			continue
+		}
+		key := route.Method + " " + route.Path
+		registered[key] = true
+
+		op := operation(doc, route.Method, route.Path)
+		if op == nil {
+			t.Errorf("%s is registered but missing from the published document", key)
+			continue
+		}
+
+		// Параметры пути роутера и документа совпадают
+		var routeParams, specParams []string
+		for _, segment := range strings.Split(route.Path, "/") {
+			if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+				routeParams = append(routeParams, segment[1:])
+			}
+		}
+		for _, p := range op.Parameters {
+			if p.In == openapi.InPath {
+				specParams = append(specParams, p.Name)
+				if !p.Required {
+					t.Errorf("%s: path parameter %s must be required", key, p.Name)
+				}
+			}
+		}
+		slices.Sort(routeParams)
+		slices.Sort(specParams)
+		if !slices.Equal(routeParams, specParams) {
+			t.Errorf("%s: path parameters %v, documented %v", key, routeParams, specParams)
+		}
+	}
+
+	documented := 0
+	operationIDs := map[string]string{}
+	for path, item := range doc.Paths {
+		for method, op := range *item {
+			documented++
+			key := strings.ToUpper(method) + " " + path
+			if op.OperationID == "" {
+				t.Errorf("%s has no operationId", key)
+			} else if other, dup := operationIDs[op.OperationID]; dup {
+				t.Errorf("%s and %s share operationId %s", key, other, op.OperationID)
+			}
+			operationIDs[op.OperationID] = key
+
+			success := false
+			for status, reply := range op.Responses {
+				if strings.HasPrefix(status, "2") {
+					success = true
+				}
+				// Проба готовности отвечает 503 тем же отчетом, что и 200
+				if status >= "400" && !slices.Contains(op.Tags, "health") {
+					if _, ok := reply.Content[ProblemContentType]; !ok {
+						t.Errorf("%s: error response %s is not %s", key, status, ProblemContentType)
+					}
+				}
+			}
+			if !success {
+				t.Errorf("%s documents no successful response", key)
+			}
+		}
+	}
+	if documented != len(registered) {
+		t.Errorf("document has %d operations, router has %d routes", documented, len(registered))
+	}
+}
+
+func TestSpecRefsResolve(t *testing.T) {
+	_, doc := loadSpec(t, nil)
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, match := range regexp.MustCompile(`"\$ref":"#/components/schemas/([^"]+)"`).FindAllStringSubmatch(string(data), -1) {
+		if _, ok := doc.Components.Schemas[match[1]]; !ok {
+			t.Errorf("reference to undefined schema %s", match[1])
+		}
+	}
+}
+
+func TestSpecPageDefaultsFollowConfig(t *testing.T) {
+	cfg := config.Default()
+	cfg.SongsPageSize = 25
+	cfg.VersesPageSize = 4
+	_, doc := loadSpec(t, cfg)
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/songs", cfg.SongsPageSize},
+		{http.MethodGet, "/api/song/text", cfg.VersesPageSize},
+	}
+
+	for _, tt := range tests {
+		op := operation(doc, tt.method, tt.path)
+		if op == nil {
+			t.Fatalf("%s %s is not documented", tt.method, tt.path)
+		}
+
+		idx := slices.IndexFunc(op.Parameters, func(p openapi.Parameter) bool { return p.Name == "limit" })
+		if idx < 0 {
+			t.Fatalf("%s %s: limit parameter is not documented", tt.method, tt.path)
+		}
+		// После разбора JSON число приходит как float64
+		if got, _ := op.Parameters[idx].Schema.Default.(float64); int(got) != tt.want {
+			t.Errorf("%s %s: limit default = %v, want %d", tt.method, tt.path, op.Parameters[idx].Schema.Default, tt.want)
+		}
+	}
+}
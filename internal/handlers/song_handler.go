@@ -1,9 +1,9 @@
 package handlers
 
 import (
-	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"music-library/internal/models"
 	"music-library/internal/service"
@@ -21,45 +21,30 @@ func NewSongHandler(songService *service.SongService) *SongHandler {
 	return &SongHandler{songService: songService}
 }
 
-// @title Music Library API
-// @version 1.0
-// @description API для управления библиотекой песен
-
-// GetSongsHandler godoc
-// @Summary Получение списка песен
-// @Description Возвращает список песен с фильтрацией и пагинацией
-// @Tags songs
-// @Accept json
-// @Produce json
-// @Param group query string false "Название группы"
-// @Param song query string false "Название песни"
-// @Param page query int false "Номер страницы" default(1)
-// @Param limit query int false "Количество записей на странице" default(10)
-// @Success 200 {array} models.Song
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Router /songs [get]
+// GetSongs возвращает список песен с фильтрацией и пагинацией
 func (h *SongHandler) GetSongs(c *gin.Context) {
 	// Извлечение параметров из запроса с значениями по умолчанию
-	group := c.Query("group")
+	filter, err := songFilter(c)
+	if err != nil {
+		respondError(c, err, "Invalid filter")
+		return
+	}
+
 	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
 	if err != nil {
 		page = 1
 	}
 
-	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	// Без лимита сервис подставляет размер страницы из конфигурации
+	limit, err := strconv.Atoi(c.Query("limit"))
 	if err != nil {
-		limit = 10
+		limit = 0
 	}
 
 	// Вызов сервисного слоя для получения списка песен
-	songs, err := h.songService.GetSongs(group, page, limit)
+	songs, err := h.songService.GetSongs(c.Request.Context(), filter, page, limit)
 	if err != nil {
-		log.Printf("Error fetching songs: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to retrieve songs",
-			"details": err.Error(),
-		})
+		respondError(c, err, "Failed to retrieve songs")
 		return
 	}
 
@@ -67,26 +52,48 @@ func (h *SongHandler) GetSongs(c *gin.Context) {
 	c.JSON(http.StatusOK, songs)
 }
 
-// GetSongTextHandler godoc
-// @Summary Получение текста песни
-// @Description Возвращает текст песни с пагинацией по куплетам
-// @Tags songs
-// @Accept json
-// @Produce json
-// @Param id path int true "ID песни"
-// @Param page query int false "Номер страницы" default(1)
-// @Param limit query int false "Количество куплетов на странице" default(10)
-// @Success 200 {object} models.SongText
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Router /songs/{id}/text [get]
+// songFilter извлекает условия отбора песен из параметров запроса
+func songFilter(c *gin.Context) (models.SongFilter, error) {
+	filter := models.SongFilter{
+		Group:    c.Query("group"),
+		SongName: c.Query("song"),
+		Sort:     c.DefaultQuery("sort", models.SortByID),
+	}
+
+	var fields []models.FieldError
+	for _, p := range []struct {
+		name string
+		dst  *models.ReleaseDate
+	}{
+		{"released_from", &filter.ReleasedFrom},
+		{"released_to", &filter.ReleasedTo},
+	} {
+		date, err := models.ParseReleaseDate(c.Query(p.name))
+		if err != nil {
+			fields = append(fields, models.NewFieldError(p.name, models.ReleaseDateFormatMessage))
+		}
+		*p.dst = date
+	}
+
+	switch filter.Sort {
+	case models.SortByID, models.SortByReleaseDate, models.SortByReleaseDateDesc:
+	default:
+		fields = append(fields, models.NewFieldError("sort", "must be one of: %s",
+			strings.Join([]string{models.SortByID, models.SortByReleaseDate, models.SortByReleaseDateDesc}, " ")))
+	}
+
+	if len(fields) > 0 {
+		return filter, models.InvalidFields(fields...)
+	}
+	return filter, nil
+}
+
+// GetSongText возвращает текст песни с пагинацией по куплетам
 func (h *SongHandler) GetSongText(c *gin.Context) {
 	// Извлечение параметров из запроса
 	songID, err := strconv.Atoi(c.Query("song_id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid song ID",
-		})
+		respondInvalidParam(c, "song_id", "Invalid song ID")
 		return
 	}
 
@@ -95,62 +102,41 @@ func (h *SongHandler) GetSongText(c *gin.Context) {
 		page = 1
 	}
 
-	limit, err := strconv.Atoi(c.DefaultQuery("limit", "3"))
+	// Без лимита сервис подставляет размер страницы из конфигурации
+	limit, err := strconv.Atoi(c.Query("limit"))
 	if err != nil {
-		limit = 3
+		limit = 0
 	}
 
 	// Получение текста песни
-	songText, err := h.songService.GetSongText(songID, page, limit)
+	songText, err := h.songService.GetSongText(c.Request.Context(), songID, page, limit)
 	if err != nil {
-		log.Printf("Error getting song text: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to retrieve song text",
-			"details": err.Error(),
-		})
+		respondError(c, err, "Failed to retrieve song text")
 		return
 	}
 
 	// Возвращение текста песни
-	c.JSON(http.StatusOK, gin.H{
-		"song_id": songID,
-		"page":    page,
-		"text":    songText,
+	c.JSON(http.StatusOK, models.SongTextResponse{
+		SongID: songID,
+		Page:   page,
+		Text:   songText,
 	})
 }
 
-// CreateSongHandler godoc
-// @Summary Добавление новой песни
-// @Description Создает новую запись о песне
-// @Tags songs
-// @Accept json
-// @Produce json
-// @Param song body models.CreateSongRequest true "Информация о песне"
-// @Success 201 {object} models.Song
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Router /songs [post]
+// CreateSong создает новую запись о песне
 func (h *SongHandler) CreateSong(c *gin.Context) {
 	// Структура для привязки входящих данных
 	var req models.CreateSongRequest
 
 	// Валидация входящего JSON
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	// Создание песни через сервисный слой
-	song, err := h.songService.CreateSong(req)
+	song, err := h.songService.CreateSong(c.Request.Context(), req, auditMeta(c))
 	if err != nil {
-		log.Printf("Error creating song: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to create song",
-			"details": err.Error(),
-		})
+		respondError(c, err, "Failed to create song")
 		return
 	}
 
@@ -158,90 +144,74 @@ func (h *SongHandler) CreateSong(c *gin.Context) {
 	c.JSON(http.StatusCreated, song)
 }
 
-// UpdateSongHandler godoc
-// @Summary Обновление информации о песне
-// @Description Обновляет существующую запись о песне
-// @Tags songs
-// @Accept json
-// @Produce json
-// @Param id path int true "ID песни"
-// @Param song body models.UpdateSongRequest true "Обновленная информация о песне"
-// @Success 200 {object} models.Song
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Router /songs/{id} [put]
+// UpdateSong обновляет существующую запись о песне
 func (h *SongHandler) UpdateSong(c *gin.Context) {
 	// Извлечение ID песни из параметров пути
 	songID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid song ID",
-		})
+		respondInvalidParam(c, "id", "Invalid song ID")
 		return
 	}
 
 	// Структура для привязки данных обновления
 	var updateData models.Song
-	if err := c.ShouldBindJSON(&updateData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+	if !bindJSON(c, &updateData) {
 		return
 	}
 
 	// Вызов сервисного метода обновления
-	if err := h.songService.UpdateSong(songID, updateData); err != nil {
-		log.Printf("Error updating song: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to update song",
-			"details": err.Error(),
-		})
+	if err := h.songService.UpdateSong(c.Request.Context(), songID, updateData, auditMeta(c)); err != nil {
+		respondError(c, err, "Failed to update song")
 		return
 	}
 
 	// Возвращение успешного ответа
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Song updated successfully",
-		"song_id": songID,
+	c.JSON(http.StatusOK, models.SongActionResponse{
+		Message: "Song updated successfully",
+		SongID:  songID,
 	})
 }
 
-// DeleteSongHandler godoc
-// @Summary Удаление песни
-// @Description Удаляет запись о песне по идентификатору
-// @Tags songs
-// @Accept json
-// @Produce json
-// @Param id path int true "ID песни"
-// @Success 204
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
-// @Router /songs/{id} [delete]
+// SetFieldLocks блокирует или разблокирует поля песни, чтобы обогащение их не перезаписывало
+func (h *SongHandler) SetFieldLocks(c *gin.Context) {
+	songID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		respondInvalidParam(c, "id", "Invalid song ID")
+		return
+	}
+
+	var req models.FieldLocksRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	song, err := h.songService.SetFieldLocks(c.Request.Context(), songID, req, auditMeta(c))
+	if err != nil {
+		respondError(c, err, "Failed to update field locks")
+		return
+	}
+
+	c.JSON(http.StatusOK, song)
+}
+
+// DeleteSong удаляет запись о песне по идентификатору
 func (h *SongHandler) DeleteSong(c *gin.Context) {
 	// Извлечение ID песни из параметров пути
 	songID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid song ID",
-		})
+		respondInvalidParam(c, "id", "Invalid song ID")
 		return
 	}
 
 	// Вызов сервисного метода удаления
-	if err := h.songService.DeleteSong(songID); err != nil {
-		log.Printf("Error deleting song: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to delete song",
-			"details": err.Error(),
-		})
+	if err := h.songService.DeleteSong(c.Request.Context(), songID, auditMeta(c)); err != nil {
+		respondError(c, err, "Failed to delete song")
 		return
 	}
 
 	// Возвращение успешного ответа
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Song deleted successfully",
-		"song_id": songID,
+	c.JSON(http.StatusOK, models.SongActionResponse{
+		Message: "Song deleted successfully",
+		SongID:  songID,
 	})
 }
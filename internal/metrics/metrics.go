@@ -0,0 +1,137 @@
+// Package metrics собирает метрики приложения в формате Prometheus: HTTP-запросы,
+// пул соединений и запросы к базе данных, вызовы внешнего API и доменные события
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace общий префикс имен метрик
+const namespace = "music_library"
+
+// Registry реестр метрик приложения; кроме собственных метрик содержит метрики
+// среды выполнения Go и процесса
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route template and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Latency of repository methods by repository and method.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"repository", "method"})
+
+	upstreamRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "external_api_requests_total",
+		Help:      "External API calls by endpoint and outcome.",
+	}, []string{"endpoint", "outcome"})
+
+	upstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "external_api_request_duration_seconds",
+		Help:      "External API call latency by endpoint.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint"})
+
+	songsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "songs_created_total",
+		Help:      "Songs created by change source.",
+	}, []string{"source"})
+
+	enrichmentJobs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "enrichment_jobs_total",
+		Help:      "Processed enrichment jobs by result: done, retry or failed.",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		dbQueryDuration,
+		upstreamRequests,
+		upstreamDuration,
+		songsCreated,
+		enrichmentJobs,
+	)
+}
+
+// Исходы вызова внешнего API
+const (
+	OutcomeOK          = "ok"
+	OutcomeNotFound    = "not_found"
+	OutcomeUnavailable = "unavailable"
+	OutcomeInvalid     = "invalid"
+	OutcomeCanceled    = "canceled"
+	OutcomeError       = "error"
+)
+
+// Результаты обработки задачи обогащения
+const (
+	EnrichmentDone   = "done"
+	EnrichmentRetry  = "retry"
+	EnrichmentFailed = "failed"
+)
+
+// Handler отдает метрики в текстовом формате Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RegisterDB добавляет статистику пула соединений из sql.DB.Stats()
+func RegisterDB(db *sql.DB, name string) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// ObserveHTTP учитывает обработанный HTTP-запрос; route — шаблон маршрута gin,
+// чтобы число рядов не зависело от значений параметров пути
+func ObserveHTTP(method, route, status string, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, status).Inc()
+	httpDuration.WithLabelValues(method, route, status).Observe(duration.Seconds())
+}
+
+// ObserveQuery учитывает время выполнения метода репозитория; вызывается через defer
+// с моментом начала: defer metrics.ObserveQuery("songs", "GetSongs", time.Now())
+func ObserveQuery(repository, method string, start time.Time) {
+	dbQueryDuration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+}
+
+// ObserveUpstream учитывает вызов адреса внешнего API с его исходом
+func ObserveUpstream(endpoint, outcome string, duration time.Duration) {
+	upstreamRequests.WithLabelValues(endpoint, outcome).Inc()
+	upstreamDuration.WithLabelValues(endpoint).Observe(duration.Seconds())
+}
+
+// SongsCreated учитывает добавленные песни
+func SongsCreated(source string, count int) {
+	songsCreated.WithLabelValues(source).Add(float64(count))
+}
+
+// EnrichmentFinished учитывает результат обработки задачи обогащения
+func EnrichmentFinished(result string) {
+	enrichmentJobs.WithLabelValues(result).Inc()
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deadline ограничивает время обработки запроса: контекст запроса отменяется по истечении
+// срока маршрута из routes (см. config.ParseRouteTimeouts) или defaultTimeout, и вместе с ним прерываются запросы к базе
+// данных и внешнему API
+func Deadline(defaultTimeout time.Duration, routes map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout, ok := routes[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			timeout = defaultTimeout
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"music-library/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute метка запросов к незарегистрированным путям, чтобы произвольные
+// адреса не порождали новые ряды метрик
+const unmatchedRoute = "unmatched"
+
+// Metrics учитывает каждый запрос в метриках HTTP по шаблону маршрута gin и статусу.
+// Подключается раньше gin.Recovery, чтобы паники попадали в метрики как ответы 500
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.ObserveHTTP(c.Request.Method, route, strconv.Itoa(c.Writer.Status()), time.Since(start))
+	}
+}
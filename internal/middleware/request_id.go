@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader заголовок с идентификатором запроса
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey ключ идентификатора запроса в контексте gin
+const requestIDKey = "request_id"
+
+// RequestID присваивает каждому запросу идентификатор, сохраняя переданный клиентом
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > 64 {
+			id = newRequestID()
+		}
+
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// GetRequestID возвращает идентификатор текущего запроса
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// newRequestID генерирует случайный идентификатор запроса
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Действия, фиксируемые в журнале аудита
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	AuditActionMerge  = "merge"
+	AuditActionLock   = "lock"
+)
+
+// AuditMeta описывает источник изменяющей операции; Source записывается как
+// происхождение измененных полей песни
+type AuditMeta struct {
+	Source    string
+	Actor     string
+	IP        string
+	Method    string
+	Route     string
+	RequestID string
+}
+
+// AuditEntry запись журнала аудита
+type AuditEntry struct {
+	ID        int64           `json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Action    string          `json:"action"`
+	SongID    int             `json:"song_id"`
+	Actor     string          `json:"actor"`
+	IP        string          `json:"ip"`
+	Method    string          `json:"method"`
+	Route     string          `json:"route"`
+	RequestID string          `json:"request_id"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditFilter параметры выборки из журнала аудита
+type AuditFilter struct {
+	Actor     string
+	Action    string
+	SongID    int
+	Route     string
+	RequestID string
+	From      time.Time
+	To        time.Time
+}
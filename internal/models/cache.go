@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// DetailsCacheEntry закэшированный ответ внешнего API для пары группа/песня
+type DetailsCacheEntry struct {
+	Key       string    `json:"key"`
+	Group     string    `json:"group"`
+	Song      string    `json:"song"`
+	Details   *Song     `json:"details,omitempty"`
+	NotFound  bool      `json:"not_found"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Expired сообщает, истек ли срок жизни записи
+func (e *DetailsCacheEntry) Expired(now time.Time) bool {
+	return !now.Before(e.ExpiresAt)
+}
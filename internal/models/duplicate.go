@@ -0,0 +1,22 @@
+package models
+
+// DuplicateOptions параметры поиска дубликатов
+type DuplicateOptions struct {
+	Group     string
+	Threshold float64
+	Lyrics    bool
+}
+
+// DuplicateCluster группа песен, вероятно являющихся дубликатами друг друга
+type DuplicateCluster struct {
+	Songs     []Song   `json:"songs"`
+	Score     float64  `json:"score"`
+	SuggestID int      `json:"suggested_keep_id"`
+	Reasons   []string `json:"reasons"`
+}
+
+// MergeSongsRequest запрос на слияние дубликатов
+type MergeSongsRequest struct {
+	KeepID       int   `json:"keep_id" binding:"required"`
+	DuplicateIDs []int `json:"duplicate_ids" binding:"required"`
+}
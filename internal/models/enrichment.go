@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Статусы обогащения песни данными внешнего API
+const (
+	EnrichmentPending = "pending"
+	EnrichmentDone    = "done"
+	EnrichmentFailed  = "failed"
+)
+
+// Статусы задачи очереди обогащения
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// EnrichmentJob задача обогащения песни
+type EnrichmentJob struct {
+	ID        int64     `json:"id"`
+	SongID    int       `json:"song_id"`
+	Group     string    `json:"group"`
+	SongName  string    `json:"song"`
+	Status    string    `json:"status"`
+	Attempts  int       `json:"attempts"`
+	Overwrite bool      `json:"overwrite"`
+	LastError string    `json:"last_error,omitempty"`
+	NextRunAt time.Time `json:"next_run_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// LockedUntil срок аренды, выданный при захвате; по нему воркер подтверждает,
+	// что задача все еще принадлежит ему
+	LockedUntil time.Time `json:"-"`
+}
+
+// EnrichRequest запрос на повторное обогащение набора песен
+type EnrichRequest struct {
+	Group          string `json:"group"`
+	Song           string `json:"song"`
+	Status         string `json:"status"`
+	IncompleteOnly bool   `json:"incomplete_only"`
+	Overwrite      bool   `json:"overwrite"`
+	Limit          int    `json:"limit"`
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Виды ошибок предметной области; обработчики HTTP сопоставляют их с кодами ответа
+var (
+	ErrNotFound            = errors.New("not found")
+	ErrValidation          = errors.New("validation failed")
+	ErrConflict            = errors.New("conflict")
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+	ErrUpstreamInvalid     = errors.New("invalid upstream response")
+)
+
+// DomainError ошибка предметной области: Message безопасно показывать клиенту,
+// а Err хранит внутреннюю причину только для журнала. Format и Args позволяют
+// перевести сообщение на язык клиента, Fields указывают на некорректные поля запроса
+type DomainError struct {
+	Kind    error
+	Message string
+	Format  string
+	Args    []interface{}
+	Fields  []FieldError
+	Err     error
+}
+
+// FieldError описывает некорректное поле запроса
+type FieldError struct {
+	Field   string        `json:"field"`
+	Message string        `json:"message"`
+	Format  string        `json:"-"`
+	Args    []interface{} `json:"-"`
+}
+
+// NewFieldError создает описание некорректного поля
+func NewFieldError(field, format string, args ...interface{}) FieldError {
+	return FieldError{Field: field, Message: fmt.Sprintf(format, args...), Format: format, Args: args}
+}
+
+func (e *DomainError) Error() string {
+	message := e.Message
+	if len(e.Fields) > 0 {
+		details := make([]string, 0, len(e.Fields))
+		for _, f := range e.Fields {
+			details = append(details, f.Field+" "+f.Message)
+		}
+		message += ": " + strings.Join(details, "; ")
+	}
+	if e.Err != nil {
+		return message + ": " + e.Err.Error()
+	}
+	return message
+}
+
+// Unwrap позволяет проверять и вид ошибки, и ее причину через errors.Is
+func (e *DomainError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+// Wrap возвращает копию ошибки с внутренней причиной
+func (e *DomainError) Wrap(err error) *DomainError {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// newDomainError создает ошибку заданного вида с форматируемым сообщением
+func newDomainError(kind error, format string, args []interface{}) *DomainError {
+	return &DomainError{Kind: kind, Message: fmt.Sprintf(format, args...), Format: format, Args: args}
+}
+
+// NotFound ошибка отсутствующего объекта
+func NotFound(format string, args ...interface{}) *DomainError {
+	return newDomainError(ErrNotFound, format, args)
+}
+
+// Validation ошибка некорректных входных данных
+func Validation(format string, args ...interface{}) *DomainError {
+	return newDomainError(ErrValidation, format, args)
+}
+
+// Conflict ошибка несовместимости с текущим состоянием данных
+func Conflict(format string, args ...interface{}) *DomainError {
+	return newDomainError(ErrConflict, format, args)
+}
+
+// UpstreamUnavailable внешний сервис недоступен
+func UpstreamUnavailable(format string, args ...interface{}) *DomainError {
+	return newDomainError(ErrUpstreamUnavailable, format, args)
+}
+
+// UpstreamInvalid внешний сервис вернул некорректный ответ
+func UpstreamInvalid(format string, args ...interface{}) *DomainError {
+	return newDomainError(ErrUpstreamInvalid, format, args)
+}
+
+// InvalidField ошибка валидации одного поля запроса
+func InvalidField(field, format string, args ...interface{}) *DomainError {
+	return InvalidFields(NewFieldError(field, format, args...))
+}
+
+// InvalidFields ошибка валидации с перечнем некорректных полей
+func InvalidFields(fields ...FieldError) *DomainError {
+	err := newDomainError(ErrValidation, "invalid fields", nil)
+	err.Fields = fields
+	return err
+}
@@ -0,0 +1,58 @@
+package models
+
+// Состояния проверок готовности
+const (
+	HealthOK       = "ok"
+	HealthDown     = "down"
+	HealthReady    = "ready"
+	HealthNotReady = "not_ready"
+)
+
+// HealthResponse ответ проверки живости процесса
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+// Readiness результат проверки готовности к приему трафика. Готовность определяют
+// база данных и миграции; внешнее API только отражается в отчете, так как обогащение
+// выполняется в фоне и его недоступность не мешает обслуживать запросы
+type Readiness struct {
+	Status      string           `json:"status"`
+	Database    DependencyStatus `json:"database"`
+	Migrations  MigrationStatus  `json:"migrations"`
+	ExternalAPI []UpstreamStatus `json:"external_api"`
+	Build       BuildInfo        `json:"build"`
+}
+
+// DependencyStatus результат проверки зависимости
+type DependencyStatus struct {
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// MigrationStatus состояние миграций схемы базы данных
+type MigrationStatus struct {
+	Status  string   `json:"status"`
+	Current string   `json:"current,omitempty"`
+	Applied int      `json:"applied"`
+	Pending []string `json:"pending"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// UpstreamStatus состояние адреса внешнего API
+type UpstreamStatus struct {
+	URL       string `json:"url"`
+	Circuit   string `json:"circuit"`
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BuildInfo сведения о сборке
+type BuildInfo struct {
+	Version   string `json:"version"`
+	GoVersion string `json:"go_version"`
+	Revision  string `json:"revision,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	Modified  bool   `json:"modified"`
+}
@@ -0,0 +1,34 @@
+package models
+
+// ImportRow строка импортируемого файла
+type ImportRow struct {
+	Group       string `json:"group"`
+	Song        string `json:"song"`
+	ReleaseDate string `json:"release_date"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// ImportRowError ошибка обработки конкретной строки файла
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// ImportOptions параметры пакетного импорта
+type ImportOptions struct {
+	Format    string
+	BatchSize int
+	Enrich    bool
+	DryRun    bool
+}
+
+// ImportReport итог пакетного импорта
+type ImportReport struct {
+	Total      int              `json:"total"`
+	Created    int              `json:"created"`
+	Duplicates int              `json:"duplicates"`
+	Failed     int              `json:"failed"`
+	Enrichment int              `json:"enrichment_queued"`
+	Errors     []ImportRowError `json:"errors"`
+}
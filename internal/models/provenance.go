@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// Поля песни, для которых хранится происхождение данных
+const (
+	FieldGroup       = "group"
+	FieldSong        = "song"
+	FieldReleaseDate = "release_date"
+	FieldText        = "text"
+	FieldLink        = "link"
+)
+
+// SongFields все поля песни с отслеживаемым происхождением
+var SongFields = []string{FieldGroup, FieldSong, FieldReleaseDate, FieldText, FieldLink}
+
+// Источники данных, не являющиеся источниками обогащения
+const (
+	SourceManual  = "manual"
+	SourceImport  = "import"
+	SourceScanner = "scanner"
+	SourceMerge   = "merge"
+	SourceUnknown = "unknown"
+)
+
+// FieldProvenance происхождение значения поля песни
+type FieldProvenance struct {
+	Source    string    `json:"source"`
+	FetchedAt time.Time `json:"fetched_at"`
+	Locked    bool      `json:"locked"`
+}
+
+// FieldLocksRequest запрос на блокировку или разблокировку полей песни
+type FieldLocksRequest struct {
+	Fields []string `json:"fields" binding:"required"`
+	Locked bool     `json:"locked"`
+}
+
+// IsSongField сообщает, является ли имя полем песни с отслеживаемым происхождением
+func IsSongField(field string) bool {
+	for _, f := range SongFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldValue возвращает значение поля песни по имени
+func (s *Song) FieldValue(field string) string {
+	switch field {
+	case FieldGroup:
+		return s.Group
+	case FieldSong:
+		return s.SongName
+	case FieldReleaseDate:
+		return s.ReleaseDate.String()
+	case FieldText:
+		return s.Text
+	case FieldLink:
+		return s.Link
+	}
+	return ""
+}
+
+// SetFieldValue устанавливает значение поля песни по имени; нераспознанная дата релиза
+// становится неизвестной
+func (s *Song) SetFieldValue(field, value string) {
+	switch field {
+	case FieldGroup:
+		s.Group = value
+	case FieldSong:
+		s.SongName = value
+	case FieldReleaseDate:
+		s.ReleaseDate, _ = ParseReleaseDate(value)
+	case FieldText:
+		s.Text = value
+	case FieldLink:
+		s.Link = value
+	}
+}
+
+// FieldLocked сообщает, заблокировано ли поле от перезаписи обогащением
+func (s *Song) FieldLocked(field string) bool {
+	return s.Provenance[field].Locked
+}
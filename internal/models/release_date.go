@@ -0,0 +1,205 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DatePrecision точность, с которой известна дата релиза
+type DatePrecision string
+
+// Точности даты релиза; пустая точность означает, что дата неизвестна
+const (
+	PrecisionUnknown DatePrecision = ""
+	PrecisionYear    DatePrecision = "year"
+	PrecisionMonth   DatePrecision = "month"
+	PrecisionDay     DatePrecision = "day"
+)
+
+// UnknownReleaseDate значение, которым клиент может явно указать неизвестную дату
+const UnknownReleaseDate = "unknown"
+
+// ReleaseDateFormatMessage описание допустимых форматов даты релиза для ошибок валидации
+const ReleaseDateFormatMessage = "must be a date in DD.MM.YYYY, ISO 8601, YYYY-MM or YYYY format"
+
+// releaseDateLayouts форматы, в которых принимается дата релиза, и точность каждого
+var releaseDateLayouts = []struct {
+	layout    string
+	precision DatePrecision
+}{
+	{"02.01.2006", PrecisionDay},
+	{"2006-01-02", PrecisionDay},
+	{time.RFC3339, PrecisionDay},
+	{"2006-01-02T15:04:05", PrecisionDay},
+	{"01.2006", PrecisionMonth},
+	{"2006-01", PrecisionMonth},
+	{"2006", PrecisionYear},
+}
+
+// ReleaseDate дата релиза с точностью до дня, месяца или года. Хранится начало периода:
+// для "1997" это 1997-01-01 с точностью year. Нулевое значение — дата неизвестна
+type ReleaseDate struct {
+	start     time.Time
+	precision DatePrecision
+	// invalid нераспознанное значение из JSON; ошибку сообщает валидация песни,
+	// которая знает имя поля
+	invalid string
+}
+
+// NewReleaseDate создает дату релиза заданной точности; время усекается до начала периода
+func NewReleaseDate(t time.Time, precision DatePrecision) ReleaseDate {
+	year, month, day := t.Date()
+	switch precision {
+	case PrecisionYear:
+		month, day = time.January, 1
+	case PrecisionMonth:
+		day = 1
+	case PrecisionDay:
+	default:
+		return ReleaseDate{}
+	}
+	return ReleaseDate{start: time.Date(year, month, day, 0, 0, 0, 0, time.UTC), precision: precision}
+}
+
+// ParseReleaseDate разбирает дату релиза в одном из поддерживаемых форматов.
+// Пустая строка и "unknown" означают неизвестную дату
+func ParseReleaseDate(value string) (ReleaseDate, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, UnknownReleaseDate) {
+		return ReleaseDate{}, nil
+	}
+
+	for _, f := range releaseDateLayouts {
+		if t, err := time.Parse(f.layout, value); err == nil {
+			return NewReleaseDate(t, f.precision), nil
+		}
+	}
+	return ReleaseDate{}, fmt.Errorf("invalid release date %q", value)
+}
+
+// LayoutPrecision определяет, с какой точностью дату задает формат в нотации Go:
+// формат с днем дает точность day, с месяцем без дня — month, иначе — year
+func LayoutPrecision(layout string) DatePrecision {
+	base := time.Date(2006, time.January, 1, 0, 0, 0, 0, time.UTC)
+	switch {
+	case base.Format(layout) != base.AddDate(0, 0, 1).Format(layout):
+		return PrecisionDay
+	case base.Format(layout) != base.AddDate(0, 1, 0).Format(layout):
+		return PrecisionMonth
+	}
+	return PrecisionYear
+}
+
+// Err возвращает ошибку, если значение пришло из JSON в неподдерживаемом формате
+func (d ReleaseDate) Err() error {
+	if d.invalid != "" {
+		return fmt.Errorf("invalid release date %s", d.invalid)
+	}
+	return nil
+}
+
+// IsZero сообщает, что дата неизвестна
+func (d ReleaseDate) IsZero() bool {
+	return d.precision == PrecisionUnknown
+}
+
+// Precision возвращает точность даты
+func (d ReleaseDate) Precision() DatePrecision {
+	return d.precision
+}
+
+// Start возвращает первый день периода
+func (d ReleaseDate) Start() time.Time {
+	return d.start
+}
+
+// End возвращает первый день после периода
+func (d ReleaseDate) End() time.Time {
+	switch d.precision {
+	case PrecisionYear:
+		return d.start.AddDate(1, 0, 0)
+	case PrecisionMonth:
+		return d.start.AddDate(0, 1, 0)
+	case PrecisionDay:
+		return d.start.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
+
+// Contains сообщает, что период даты целиком включает период other
+func (d ReleaseDate) Contains(other ReleaseDate) bool {
+	if d.IsZero() || other.IsZero() {
+		return false
+	}
+	return !other.start.Before(d.start) && !other.End().After(d.End())
+}
+
+// MorePrecise сообщает, что дата известна точнее, чем other
+func (d ReleaseDate) MorePrecise(other ReleaseDate) bool {
+	return precisionRank(d.precision) > precisionRank(other.precision)
+}
+
+func precisionRank(p DatePrecision) int {
+	switch p {
+	case PrecisionYear:
+		return 1
+	case PrecisionMonth:
+		return 2
+	case PrecisionDay:
+		return 3
+	}
+	return 0
+}
+
+// String возвращает дату в виде ГГГГ-ММ-ДД, ГГГГ-ММ или ГГГГ в зависимости от точности;
+// для неизвестной даты — пустую строку
+func (d ReleaseDate) String() string {
+	switch d.precision {
+	case PrecisionYear:
+		return d.start.Format("2006")
+	case PrecisionMonth:
+		return d.start.Format("2006-01")
+	case PrecisionDay:
+		return d.start.Format("2006-01-02")
+	}
+	return ""
+}
+
+// MarshalJSON сериализует неизвестную дату как null
+func (d ReleaseDate) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON принимает строку в одном из поддерживаемых форматов, null или "unknown".
+// Нераспознанное значение не прерывает разбор запроса, а сохраняется для Err
+func (d *ReleaseDate) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = ReleaseDate{}
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		*d = ReleaseDate{invalid: string(data)}
+		return nil
+	}
+
+	parsed, err := ParseReleaseDate(value)
+	if err != nil {
+		*d = ReleaseDate{invalid: strconv.Quote(value)}
+		return nil
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalText используется в текстовых форматах выгрузки
+func (d ReleaseDate) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
@@ -0,0 +1,220 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func mustParseReleaseDate(t *testing.T, value string) ReleaseDate {
+	t.Helper()
+
+	d, err := ParseReleaseDate(value)
+	if err != nil {
+		t.Fatalf("ParseReleaseDate(%q): %v", value, err)
+	}
+	return d
+}
+
+func TestParseReleaseDate(t *testing.T) {
+	tests := []struct {
+		value     string
+		want      string
+		precision DatePrecision
+	}{
+		{"16.07.2006", "2006-07-16", PrecisionDay},
+		{"2006-07-16", "2006-07-16", PrecisionDay},
+		{"2006-07-16T23:30:00+03:00", "2006-07-16", PrecisionDay},
+		{"2006-07-16T23:30:00", "2006-07-16", PrecisionDay},
+		{"07.2006", "2006-07", PrecisionMonth},
+		{"2006-07", "2006-07", PrecisionMonth},
+		{"2006", "2006", PrecisionYear},
+		{" 1997 ", "1997", PrecisionYear},
+		{"", "", PrecisionUnknown},
+		{"unknown", "", PrecisionUnknown},
+		{"Unknown", "", PrecisionUnknown},
+	}
+
+	for _, tt := range tests {
+		d := mustParseReleaseDate(t, tt.value)
+		if d.String() != tt.want || d.Precision() != tt.precision {
+			t.Errorf("ParseReleaseDate(%q) = %q (%s), want %q (%s)", tt.value, d.String(), d.Precision(), tt.want, tt.precision)
+		}
+	}
+}
+
+func TestParseReleaseDateInvalid(t *testing.T) {
+	for _, value := range []string{"yesterday", "2006-13", "31.02.2006", "06", "2006/07/16"} {
+		if d, err := ParseReleaseDate(value); err == nil {
+			t.Errorf("ParseReleaseDate(%q) = %q, want error", value, d.String())
+		}
+	}
+}
+
+func TestReleaseDateStartEnd(t *testing.T) {
+	tests := []struct {
+		value      string
+		start, end string
+	}{
+		{"2006", "2006-01-01", "2007-01-01"},
+		{"2006-12", "2006-12-01", "2007-01-01"},
+		{"2006-01", "2006-01-01", "2006-02-01"},
+		{"2004-02", "2004-02-01", "2004-03-01"},
+		{"2004-02-28", "2004-02-28", "2004-02-29"},
+		{"2004-02-29", "2004-02-29", "2004-03-01"},
+		{"2006-12-31", "2006-12-31", "2007-01-01"},
+	}
+
+	for _, tt := range tests {
+		d := mustParseReleaseDate(t, tt.value)
+		start, end := d.Start().Format(time.DateOnly), d.End().Format(time.DateOnly)
+		if start != tt.start || end != tt.end {
+			t.Errorf("%s: period [%s, %s), want [%s, %s)", tt.value, start, end, tt.start, tt.end)
+		}
+	}
+
+	if end := (ReleaseDate{}).End(); !end.IsZero() {
+		t.Errorf("unknown date End() = %s, want zero time", end)
+	}
+}
+
+func TestReleaseDateContains(t *testing.T) {
+	tests := []struct {
+		outer, inner string
+		want         bool
+	}{
+		{"2006", "2006", true},
+		{"2006", "2006-07", true},
+		{"2006", "2006-12-31", true},
+		{"2006-07", "2006-07-01", true},
+		{"2006-07", "2006-07-31", true},
+		{"2006-07", "2006-08-01", false},
+		{"2006", "2007-01-01", false},
+		{"2006-07", "2006", false},
+		{"2006-07-16", "2006-07", false},
+		{"2006-07-16", "2006-07-16", true},
+		{"2006", "", false},
+		{"", "2006", false},
+	}
+
+	for _, tt := range tests {
+		outer, inner := mustParseReleaseDate(t, tt.outer), mustParseReleaseDate(t, tt.inner)
+		if got := outer.Contains(inner); got != tt.want {
+			t.Errorf("%q.Contains(%q) = %t, want %t", tt.outer, tt.inner, got, tt.want)
+		}
+	}
+}
+
+func TestReleaseDateMorePrecise(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"2006-07-16", "2006-07", true},
+		{"2006-07", "2006", true},
+		{"2006", "", true},
+		{"2006", "2006-07", false},
+		{"2006-07-16", "1997-01-02", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		a, b := mustParseReleaseDate(t, tt.a), mustParseReleaseDate(t, tt.b)
+		if got := a.MorePrecise(b); got != tt.want {
+			t.Errorf("%q.MorePrecise(%q) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLayoutPrecision(t *testing.T) {
+	// Точность встроенных форматов совпадает с выводом по самому формату
+	for _, f := range releaseDateLayouts {
+		if got := LayoutPrecision(f.layout); got != f.precision {
+			t.Errorf("LayoutPrecision(%q) = %s, want %s", f.layout, got, f.precision)
+		}
+	}
+
+	tests := []struct {
+		layout string
+		want   DatePrecision
+	}{
+		{"2006/01/02", PrecisionDay},
+		{"Jan 2, 2006", PrecisionDay},
+		{"2006.002", PrecisionDay},
+		{"January 2006", PrecisionMonth},
+		{"01/06", PrecisionMonth},
+		{"06", PrecisionYear},
+	}
+	for _, tt := range tests {
+		if got := LayoutPrecision(tt.layout); got != tt.want {
+			t.Errorf("LayoutPrecision(%q) = %s, want %s", tt.layout, got, tt.want)
+		}
+	}
+}
+
+func TestReleaseDateJSON(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`"16.07.2006"`, `"2006-07-16"`},
+		{`"2006-07"`, `"2006-07"`},
+		{`"1997"`, `"1997"`},
+		{`"unknown"`, `null`},
+		{`""`, `null`},
+		{`null`, `null`},
+	}
+
+	for _, tt := range tests {
+		var d ReleaseDate
+		if err := json.Unmarshal([]byte(tt.input), &d); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", tt.input, err)
+		}
+		if err := d.Err(); err != nil {
+			t.Errorf("Unmarshal(%s): Err() = %v, want nil", tt.input, err)
+		}
+
+		data, err := json.Marshal(d)
+		if err != nil {
+			t.Fatalf("Marshal(%s): %v", tt.input, err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("round trip of %s = %s, want %s", tt.input, data, tt.want)
+		}
+
+		// Повторный разбор сериализованного значения дает ту же дату
+		var again ReleaseDate
+		if err := json.Unmarshal(data, &again); err != nil || again != d {
+			t.Errorf("second round trip of %s = %q, %v; want %q", tt.input, again.String(), err, d.String())
+		}
+	}
+}
+
+func TestReleaseDateJSONInvalid(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{`"yesterday"`, `invalid release date "yesterday"`},
+		{`5`, `invalid release date 5`},
+		{`{"year": 2006}`, `invalid release date {"year": 2006}`},
+	}
+
+	for _, tt := range tests {
+		var payload struct {
+			ReleaseDate ReleaseDate `json:"release_date"`
+		}
+		// Нераспознанная дата не прерывает разбор всего документа
+		if err := json.Unmarshal([]byte(`{"release_date": `+tt.input+`}`), &payload); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", tt.input, err)
+		}
+
+		d := payload.ReleaseDate
+		if !d.IsZero() {
+			t.Errorf("Unmarshal(%s): date = %q, want unknown", tt.input, d.String())
+		}
+		if err := d.Err(); err == nil || err.Error() != tt.wantErr {
+			t.Errorf("Unmarshal(%s): Err() = %v, want %q", tt.input, err, tt.wantErr)
+		}
+	}
+}
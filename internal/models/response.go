@@ -0,0 +1,31 @@
+package models
+
+// SongTextResponse страница текста песни
+type SongTextResponse struct {
+	SongID int    `json:"song_id"`
+	Page   int    `json:"page"`
+	Text   string `json:"text"`
+}
+
+// SongActionResponse результат операции над песней
+type SongActionResponse struct {
+	Message string `json:"message"`
+	SongID  int    `json:"song_id"`
+}
+
+// JobActionResponse результат операции над задачей обогащения
+type JobActionResponse struct {
+	Message string `json:"message"`
+	JobID   int64  `json:"job_id"`
+}
+
+// CountResponse результат пакетной операции с числом затронутых записей
+type CountResponse struct {
+	Message string `json:"message"`
+	Count   int64  `json:"count"`
+}
+
+// MessageResponse результат операции без дополнительных данных
+type MessageResponse struct {
+	Message string `json:"message"`
+}
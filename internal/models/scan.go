@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// ScannedFile состояние аудиофайла на момент последнего сканирования
+type ScannedFile struct {
+	Path    string
+	ModTime time.Time
+	Size    int64
+	SongID  int
+}
+
+// ScanOptions параметры сканирования папки с музыкой
+type ScanOptions struct {
+	Full   bool
+	DryRun bool
+}
+
+// ScanFileError ошибка обработки конкретного файла
+type ScanFileError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// ScanReport итог сканирования папки
+type ScanReport struct {
+	Files     int             `json:"files"`
+	Unchanged int             `json:"unchanged"`
+	Created   int             `json:"created"`
+	Updated   int             `json:"updated"`
+	Failed    int             `json:"failed"`
+	Errors    []ScanFileError `json:"errors"`
+}
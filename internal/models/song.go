@@ -1,15 +1,36 @@
 package models
 
 type Song struct {
-	ID          int    `json:"id" db:"id"`
-	Group       string `json:"group" db:"group"`
-	SongName    string `json:"song" db:"song_name"`
-	ReleaseDate string `json:"release_date" db:"release_date"`
-	Text        string `json:"text" db:"text"`
-	Link        string `json:"link" db:"link"`
+	ID          int         `json:"id" db:"id"`
+	Group       string      `json:"group" db:"group"`
+	SongName    string      `json:"song" db:"song_name"`
+	ReleaseDate ReleaseDate `json:"release_date" db:"release_date"`
+	Text        string      `json:"text" db:"text"`
+	Link        string      `json:"link" db:"link"`
+
+	EnrichmentStatus string `json:"enrichment_status" db:"enrichment_status"`
+
+	Provenance map[string]FieldProvenance `json:"provenance,omitempty" db:"-"`
 }
 
 type CreateSongRequest struct {
 	Group string `json:"group" binding:"required"`
 	Song  string `json:"song" binding:"required"`
 }
+
+// Порядок сортировки песен
+const (
+	SortByID              = "id"
+	SortByReleaseDate     = "release_date"
+	SortByReleaseDateDesc = "-release_date"
+)
+
+// SongFilter условия отбора песен для списков и выгрузки. Диапазон дат релиза учитывает
+// точность: песня попадает в выборку, только если ее период целиком лежит в диапазоне
+type SongFilter struct {
+	Group        string
+	SongName     string
+	ReleasedFrom ReleaseDate
+	ReleasedTo   ReleaseDate
+	Sort         string
+}
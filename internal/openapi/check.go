@@ -0,0 +1,57 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CheckRoutes сверяет зарегистрированные маршруты gin с операциями документа.
+// Маршруты из ignore ("GET /api/docs/*filepath") не документируются и не проверяются
+func (d *Document) CheckRoutes(routes gin.RoutesInfo, ignore ...string) error {
+	skip := make(map[string]bool, len(ignore))
+	for _, route := range ignore {
+		skip[route] = true
+	}
+
+	registered := map[string]bool{}
+	for _, route := range routes {
+		if skip[route.Method+" "+route.Path] {
+			continue
+		}
+		registered[route.Method+" "+PathFromGin(route.Path)] = true
+	}
+
+	documented := map[string]bool{}
+	for path, item := range d.Paths {
+		for method := range *item {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	var errs []error
+	for _, route := range sortedKeys(registered) {
+		if !documented[route] {
+			errs = append(errs, fmt.Errorf("route %s is registered but missing from the OpenAPI document", route))
+		}
+	}
+	for _, route := range sortedKeys(documented) {
+		if !registered[route] {
+			errs = append(errs, fmt.Errorf("operation %s is documented but no route is registered", route))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,160 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Расположение параметров
+const (
+	InPath  = "path"
+	InQuery = "query"
+)
+
+// Типы параметров
+const (
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+)
+
+// JSONContentType тип содержимого по умолчанию для тел запросов и ответов
+const JSONContentType = "application/json"
+
+// Endpoint описание маршрута, из которого строится операция документа
+type Endpoint struct {
+	Method      string
+	Path        string // путь в синтаксисе gin, например /api/song/:id
+	ID          string
+	Tag         string
+	Summary     string
+	Description string
+	Params      []Param
+	Body        *Body
+	Responses   []Reply
+}
+
+// Param параметр пути или строки запроса
+type Param struct {
+	Name        string
+	In          string
+	Type        string
+	Description string
+	Required    bool
+	Default     any
+	Enum        []string
+}
+
+// Body тело запроса; Model — значение описываемого типа, nil означает произвольные данные
+type Body struct {
+	Description  string
+	Required     bool
+	ContentTypes []string
+	Model        any
+}
+
+// Reply ответ операции; без ContentTypes ответ не имеет тела
+type Reply struct {
+	Status       int
+	Description  string
+	ContentTypes []string
+	Model        any
+}
+
+// NewDocument строит документ OpenAPI по описаниям маршрутов
+func NewDocument(info Info, endpoints []Endpoint) *Document {
+	doc := &Document{
+		OpenAPI:    Version,
+		Info:       info,
+		Paths:      map[string]*PathItem{},
+		Components: Components{Schemas: map[string]*Schema{}},
+	}
+
+	for _, e := range endpoints {
+		path := PathFromGin(e.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		(*item)[strings.ToLower(e.Method)] = doc.operation(e)
+	}
+
+	return doc
+}
+
+func (d *Document) operation(e Endpoint) *Operation {
+	op := &Operation{
+		OperationID: e.ID,
+		Summary:     e.Summary,
+		Description: e.Description,
+		Responses:   map[string]*Response{},
+	}
+	if e.Tag != "" {
+		op.Tags = []string{e.Tag}
+	}
+
+	for _, p := range e.Params {
+		schema := &Schema{Type: p.Type, Default: p.Default, Enum: p.Enum}
+		if p.Type == TypeInteger {
+			schema.Format = "int32"
+		}
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        p.Name,
+			In:          p.In,
+			Description: p.Description,
+			// Параметры пути обязательны по спецификации
+			Required: p.Required || p.In == InPath,
+			Schema:   schema,
+		})
+	}
+
+	if e.Body != nil {
+		op.RequestBody = &RequestBody{
+			Description: e.Body.Description,
+			Required:    e.Body.Required,
+			Content:     d.content(e.Body.ContentTypes, e.Body.Model),
+		}
+	}
+
+	for _, r := range e.Responses {
+		resp := &Response{Description: r.Description}
+		if len(r.ContentTypes) > 0 {
+			resp.Content = d.content(r.ContentTypes, r.Model)
+		}
+		op.Responses[strconv.Itoa(r.Status)] = resp
+	}
+
+	return op
+}
+
+// content описывает тело для каждого типа содержимого
+func (d *Document) content(contentTypes []string, model any) map[string]MediaType {
+	if len(contentTypes) == 0 {
+		contentTypes = []string{JSONContentType}
+	}
+
+	schema := &Schema{Type: "string", Format: "binary"}
+	if model != nil {
+		schema = d.schemaFor(reflect.TypeOf(model))
+	}
+
+	content := make(map[string]MediaType, len(contentTypes))
+	for _, ct := range contentTypes {
+		content[ct] = MediaType{Schema: schema}
+	}
+	return content
+}
+
+// PathFromGin переводит путь gin (/song/:id, /docs/*file) в шаблон OpenAPI (/song/{id})
+func PathFromGin(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,114 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schemaFor возвращает схему значения; именованные структуры попадают в components
+// и подставляются ссылкой
+func (d *Document) schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		// Произвольный JSON
+		return &Schema{}
+	}
+
+	if t.Implements(textMarshalerType) {
+		// Значение с собственным текстовым представлением, например дата релиза
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: d.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+		if _, ok := d.Components.Schemas[t.Name()]; !ok {
+			// Заглушка до заполнения защищает от бесконечной рекурсии на циклических типах
+			d.Components.Schemas[t.Name()] = &Schema{}
+			*d.Components.Schemas[t.Name()] = *d.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	}
+
+	return &Schema{}
+}
+
+// structSchema описывает поля структуры по их JSON-тегам; поля с binding:"required"
+// считаются обязательными
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				inner := d.structSchema(embedded)
+				for prop, s := range inner.Properties {
+					schema.Properties[prop] = s
+				}
+				schema.Required = append(schema.Required, inner.Required...)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = d.schemaFor(field.Type)
+
+		for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+			if rule == "required" {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+	}
+
+	return schema
+}
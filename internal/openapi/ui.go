@@ -0,0 +1,67 @@
+package openapi
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// swaggerInitializer заменяет initializer из дистрибутива Swagger UI, который
+// по умолчанию открывает демонстрационный petstore
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: %q,
+    dom_id: '#swagger-ui',
+    deepLinking: true,
+    presets: [
+      SwaggerUIBundle.presets.apis,
+      SwaggerUIStandalonePreset
+    ],
+    plugins: [
+      SwaggerUIBundle.plugins.DownloadUrl
+    ],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// Mount публикует документ по prefix/openapi.json и Swagger UI по prefix/docs/.
+// Возвращает добавленные маршруты, чтобы исключить их из CheckRoutes
+func Mount(router gin.IRoutes, prefix string, doc *Document) []string {
+	specPath := prefix + "/openapi.json"
+	docsPath := prefix + "/docs/*filepath"
+
+	router.GET(specPath, func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	})
+	router.GET(docsPath, swaggerUI(specPath))
+
+	return []string{http.MethodGet + " " + specPath, http.MethodGet + " " + docsPath}
+}
+
+// swaggerUI раздает встроенную в бинарник статику Swagger UI
+func swaggerUI(specURL string) gin.HandlerFunc {
+	initializer := fmt.Sprintf(swaggerInitializer, specURL)
+	assets := http.FS(swaggerFiles.FS)
+
+	return func(c *gin.Context) {
+		switch file := strings.TrimPrefix(c.Param("filepath"), "/"); file {
+		case "", "index.html":
+			// http.FileServer перенаправляет index.html на каталог, поэтому страница отдается напрямую
+			page, err := fs.ReadFile(swaggerFiles.FS, "index.html")
+			if err != nil {
+				c.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+			c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+		case "swagger-initializer.js":
+			c.Data(http.StatusOK, "application/javascript; charset=utf-8", []byte(initializer))
+		default:
+			c.FileFromFS(file, assets)
+		}
+	}
+}
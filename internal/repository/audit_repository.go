@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"strings"
+	"time"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository создает новый экземпляр репозитория журнала аудита
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// insertAuditEntry записывает событие аудита в рамках переданной транзакции
+func insertAuditEntry(ctx context.Context, tx *sql.Tx, action string, songID int, meta models.AuditMeta, before, after *models.Song) error {
+	beforeJSON, err := snapshotJSON(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := snapshotJSON(after)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO audit_log (action, song_id, actor, ip, method, route, request_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err = tx.ExecContext(ctx,
+		query,
+		action,
+		songID,
+		meta.Actor,
+		meta.IP,
+		meta.Method,
+		meta.Route,
+		meta.RequestID,
+		beforeJSON,
+		afterJSON,
+	)
+	if err != nil {
+		log.Printf("Error writing audit entry: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// snapshotJSON сериализует снимок песни, nil превращается в SQL NULL
+func snapshotJSON(song *models.Song) (interface{}, error) {
+	if song == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(song)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit snapshot: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// buildAuditQuery формирует запрос к журналу с учетом фильтров
+func buildAuditQuery(filter models.AuditFilter) (string, []interface{}) {
+	query := `SELECT id, created_at, action, COALESCE(song_id, 0), actor,
+                     COALESCE(ip, ''), COALESCE(method, ''), COALESCE(route, ''),
+                     COALESCE(request_id, ''), before, after
+              FROM audit_log
+              WHERE 1=1`
+
+	var args []interface{}
+	var conditions []string
+
+	if filter.Actor != "" {
+		conditions = append(conditions, fmt.Sprintf("actor = $%d", len(args)+1))
+		args = append(args, filter.Actor)
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, fmt.Sprintf("action = $%d", len(args)+1))
+		args = append(args, filter.Action)
+	}
+	if filter.SongID > 0 {
+		conditions = append(conditions, fmt.Sprintf("song_id = $%d", len(args)+1))
+		args = append(args, filter.SongID)
+	}
+	if filter.Route != "" {
+		conditions = append(conditions, fmt.Sprintf("route = $%d", len(args)+1))
+		args = append(args, filter.Route)
+	}
+	if filter.RequestID != "" {
+		conditions = append(conditions, fmt.Sprintf("request_id = $%d", len(args)+1))
+		args = append(args, filter.RequestID)
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)+1))
+		args = append(args, filter.From)
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)+1))
+		args = append(args, filter.To)
+	}
+
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY id"
+
+	return query, args
+}
+
+// scanAuditEntry читает одну запись журнала из результата запроса
+func scanAuditEntry(rows *sql.Rows) (models.AuditEntry, error) {
+	var entry models.AuditEntry
+	var before, after []byte
+
+	err := rows.Scan(
+		&entry.ID, &entry.CreatedAt, &entry.Action, &entry.SongID, &entry.Actor,
+		&entry.IP, &entry.Method, &entry.Route, &entry.RequestID, &before, &after,
+	)
+	if err != nil {
+		return entry, err
+	}
+
+	if len(before) > 0 {
+		entry.Before = json.RawMessage(before)
+	}
+	if len(after) > 0 {
+		entry.After = json.RawMessage(after)
+	}
+
+	return entry, nil
+}
+
+// ListEntries возвращает записи журнала с фильтрацией и пагинацией
+func (r *AuditRepository) ListEntries(ctx context.Context, filter models.AuditFilter, page, limit int) ([]models.AuditEntry, error) {
+	defer metrics.ObserveQuery("audit", "ListEntries", time.Now())
+
+	query, args := buildAuditQuery(filter)
+
+	query += " LIMIT $" + fmt.Sprintf("%d", len(args)+1)
+	args = append(args, limit)
+
+	query += " OFFSET $" + fmt.Sprintf("%d", len(args)+1)
+	args = append(args, (page-1)*limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error querying audit log: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			log.Printf("Error scanning audit row: %v", err)
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// StreamEntries передает записи журнала в fn по одной, не накапливая их в памяти
+func (r *AuditRepository) StreamEntries(ctx context.Context, filter models.AuditFilter, fn func(models.AuditEntry) error) error {
+	defer metrics.ObserveQuery("audit", "StreamEntries", time.Now())
+
+	query, args := buildAuditQuery(filter)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error querying audit log: %v", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			log.Printf("Error scanning audit row: %v", err)
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
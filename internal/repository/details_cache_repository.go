@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"time"
+)
+
+// DetailsCacheRepository постоянное хранилище кэша ответов внешнего API
+type DetailsCacheRepository struct {
+	db *sql.DB
+}
+
+// NewDetailsCacheRepository создает новый экземпляр репозитория кэша
+func NewDetailsCacheRepository(db *sql.DB) *DetailsCacheRepository {
+	return &DetailsCacheRepository{db: db}
+}
+
+// cacheColumns список колонок записи кэша в порядке, ожидаемом scanCacheEntry
+const cacheColumns = `key, group_name, song_name, details, not_found, expires_at, created_at`
+
+// scanCacheEntry читает запись кэша из строки результата запроса
+func scanCacheEntry(row rowScanner) (*models.DetailsCacheEntry, error) {
+	var entry models.DetailsCacheEntry
+	var details []byte
+
+	err := row.Scan(
+		&entry.Key, &entry.Group, &entry.Song, &details,
+		&entry.NotFound, &entry.ExpiresAt, &entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(details) > 0 {
+		entry.Details = &models.Song{}
+		if err := json.Unmarshal(details, entry.Details); err != nil {
+			return nil, fmt.Errorf("failed to decode cached details: %w", err)
+		}
+	}
+
+	return &entry, nil
+}
+
+// Get возвращает непросроченную запись кэша, nil означает промах
+func (r *DetailsCacheRepository) Get(ctx context.Context, key string) (*models.DetailsCacheEntry, error) {
+	defer metrics.ObserveQuery("details_cache", "Get", time.Now())
+
+	query := `SELECT ` + cacheColumns + ` FROM details_cache WHERE key = $1 AND expires_at > now()`
+
+	entry, err := scanCacheEntry(r.db.QueryRowContext(ctx, query, key))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Error reading details cache: %v", err)
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Set сохраняет или заменяет запись кэша
+func (r *DetailsCacheRepository) Set(ctx context.Context, entry *models.DetailsCacheEntry) error {
+	defer metrics.ObserveQuery("details_cache", "Set", time.Now())
+
+	var details interface{}
+	if entry.Details != nil {
+		data, err := json.Marshal(entry.Details)
+		if err != nil {
+			return fmt.Errorf("failed to encode cached details: %w", err)
+		}
+		details = string(data)
+	}
+
+	query := `
+		INSERT INTO details_cache (key, group_name, song_name, details, not_found, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (key) DO UPDATE
+		SET group_name = EXCLUDED.group_name, song_name = EXCLUDED.song_name,
+		    details = EXCLUDED.details, not_found = EXCLUDED.not_found,
+		    expires_at = EXCLUDED.expires_at, created_at = EXCLUDED.created_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query, entry.Key, entry.Group, entry.Song, details,
+		entry.NotFound, entry.ExpiresAt, entry.CreatedAt)
+	if err != nil {
+		log.Printf("Error writing details cache: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteExpired удаляет просроченные записи кэша и возвращает их число
+func (r *DetailsCacheRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	defer metrics.ObserveQuery("details_cache", "DeleteExpired", time.Now())
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM details_cache WHERE expires_at <= now()`)
+	if err != nil {
+		log.Printf("Error deleting expired details cache entries: %v", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Delete удаляет запись кэша
+func (r *DetailsCacheRepository) Delete(ctx context.Context, key string) error {
+	defer metrics.ObserveQuery("details_cache", "Delete", time.Now())
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM details_cache WHERE key = $1`, key); err != nil {
+		log.Printf("Error deleting details cache entry: %v", err)
+		return err
+	}
+	return nil
+}
+
+// List возвращает последние непросроченные записи кэша
+func (r *DetailsCacheRepository) List(ctx context.Context, limit int) ([]models.DetailsCacheEntry, error) {
+	defer metrics.ObserveQuery("details_cache", "List", time.Now())
+
+	query := `SELECT ` + cacheColumns + `
+              FROM details_cache
+              WHERE expires_at > now()
+              ORDER BY created_at DESC
+              LIMIT $1`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		log.Printf("Error listing details cache: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.DetailsCacheEntry
+	for rows.Next() {
+		entry, err := scanCacheEntry(rows)
+		if err != nil {
+			log.Printf("Error scanning details cache entry: %v", err)
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// Purge очищает кэш полностью
+func (r *DetailsCacheRepository) Purge(ctx context.Context) error {
+	defer metrics.ObserveQuery("details_cache", "Purge", time.Now())
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM details_cache`); err != nil {
+		log.Printf("Error purging details cache: %v", err)
+		return err
+	}
+	return nil
+}
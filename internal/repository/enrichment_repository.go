@@ -0,0 +1,463 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"strings"
+	"time"
+)
+
+// ErrJobLeaseLost задача больше не принадлежит воркеру: аренда истекла, и задачу
+// забрал другой воркер или она уже закрыта
+var ErrJobLeaseLost = errors.New("enrichment job lease is lost")
+
+type EnrichmentRepository struct {
+	db *sql.DB
+}
+
+// NewEnrichmentRepository создает новый экземпляр репозитория очереди обогащения
+func NewEnrichmentRepository(db *sql.DB) *EnrichmentRepository {
+	return &EnrichmentRepository{db: db}
+}
+
+// jobColumns список колонок задачи в порядке, ожидаемом scanJob
+const jobColumns = `j.id, j.song_id, s."group", s.song_name, j.status, j.attempts, j.overwrite,
+                    COALESCE(j.last_error, ''), j.next_run_at, j.created_at, j.updated_at, j.locked_until`
+
+// scanJob читает задачу обогащения из строки результата запроса
+func scanJob(row rowScanner) (models.EnrichmentJob, error) {
+	var job models.EnrichmentJob
+	var lockedUntil sql.NullTime
+	err := row.Scan(
+		&job.ID, &job.SongID, &job.Group, &job.SongName, &job.Status, &job.Attempts,
+		&job.Overwrite, &job.LastError, &job.NextRunAt, &job.CreatedAt, &job.UpdatedAt,
+		&lockedUntil,
+	)
+	job.LockedUntil = lockedUntil.Time
+	return job, err
+}
+
+// enqueueEnrichmentJob ставит песню в очередь обогащения в рамках транзакции;
+// если активная задача уже есть, новая не создается
+func enqueueEnrichmentJob(ctx context.Context, tx *sql.Tx, songID int) error {
+	query := `
+		INSERT INTO enrichment_jobs (song_id)
+		VALUES ($1)
+		ON CONFLICT (song_id) WHERE status IN ('queued', 'running') DO NOTHING
+	`
+
+	if _, err := tx.ExecContext(ctx, query, songID); err != nil {
+		log.Printf("Error enqueueing enrichment job: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ClaimJobs забирает готовые к выполнению задачи, включая задачи с истекшей арендой
+func (r *EnrichmentRepository) ClaimJobs(ctx context.Context, limit int, lease time.Duration) ([]models.EnrichmentJob, error) {
+	defer metrics.ObserveQuery("enrichment", "ClaimJobs", time.Now())
+
+	query := `
+		WITH claimed AS (
+			UPDATE enrichment_jobs
+			SET status = 'running', attempts = attempts + 1,
+			    locked_until = now() + make_interval(secs => $2), updated_at = now()
+			WHERE id IN (
+				SELECT id FROM enrichment_jobs
+				WHERE (status = 'queued' AND next_run_at <= now())
+				   OR (status = 'running' AND locked_until < now())
+				ORDER BY next_run_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT ` + jobColumns + `
+		FROM claimed j
+		JOIN songs s ON s.id = j.song_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		log.Printf("Error claiming enrichment jobs: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []models.EnrichmentJob
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			log.Printf("Error scanning enrichment job: %v", err)
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// CompleteJob применяет к песне результат обогащения и закрывает задачу в одной транзакции
+func (r *EnrichmentRepository) CompleteJob(ctx context.Context, job models.EnrichmentJob, meta models.AuditMeta, apply func(song models.Song) models.Song) error {
+	defer metrics.ObserveQuery("enrichment", "CompleteJob", time.Now())
+
+	updateQuery := `
+		UPDATE songs
+		SET release_date = $1::date, release_date_precision = $2, text = $3, link = $4,
+		    enrichment_status = 'done', enriched_at = now()
+		WHERE id = $5
+		RETURNING ` + songColumns
+
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		// Задача закрывается первой: если ее уже забрал другой воркер, песня не меняется
+		if err := finishJob(ctx, tx, job, models.JobDone, ""); err != nil {
+			return err
+		}
+
+		before, err := getSongForUpdate(ctx, tx, job.SongID)
+		if err != nil {
+			return err
+		}
+		// Происхождение нужно функции apply, чтобы не трогать заблокированные поля
+		if err := attachSongProvenance(ctx, tx, before); err != nil {
+			return err
+		}
+
+		// apply получает копию с собственной картой происхождения, чтобы снимок
+		// before в журнале аудита остался прежним
+		snapshot := *before
+		snapshot.Provenance = maps.Clone(before.Provenance)
+		result := apply(snapshot)
+		releaseDate, precision := releaseDateArgs(result.ReleaseDate)
+		after, err := scanSong(tx.QueryRowContext(ctx,
+			updateQuery,
+			releaseDate,
+			precision,
+			result.Text,
+			result.Link,
+			job.SongID,
+		))
+		if err != nil {
+			log.Printf("Error saving enriched song: %v", err)
+			return err
+		}
+
+		if err := recordProvenance(ctx, tx, before, &after, result.Provenance, meta.Source); err != nil {
+			return err
+		}
+
+		if err := attachSongProvenance(ctx, tx, &after); err != nil {
+			return err
+		}
+
+		return insertAuditEntry(ctx, tx, models.AuditActionUpdate, job.SongID, meta, before, &after)
+	})
+}
+
+// RescheduleJob возвращает задачу в очередь для повторной попытки, если воркер
+// все еще владеет ею
+func (r *EnrichmentRepository) RescheduleJob(ctx context.Context, job models.EnrichmentJob, lastError string, nextRunAt time.Time) error {
+	defer metrics.ObserveQuery("enrichment", "RescheduleJob", time.Now())
+
+	query := `
+		UPDATE enrichment_jobs
+		SET status = 'queued', last_error = $3, next_run_at = $4,
+		    locked_until = NULL, updated_at = now()
+		WHERE id = $1 AND status = 'running' AND locked_until = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, job.ID, job.LockedUntil, lastError, nextRunAt)
+	if err != nil {
+		log.Printf("Error rescheduling enrichment job: %v", err)
+		return err
+	}
+
+	return checkJobOwned(result)
+}
+
+// FailJob окончательно помечает задачу и песню как необогащенные
+func (r *EnrichmentRepository) FailJob(ctx context.Context, job models.EnrichmentJob, lastError string) error {
+	defer metrics.ObserveQuery("enrichment", "FailJob", time.Now())
+
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := finishJob(ctx, tx, job, models.JobFailed, lastError); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, `UPDATE songs SET enrichment_status = 'failed' WHERE id = $1`, job.SongID)
+		if err != nil {
+			log.Printf("Error marking song enrichment failed: %v", err)
+		}
+		return err
+	})
+}
+
+// finishJob переводит задачу в конечный статус, если воркер все еще владеет ею:
+// задача выполняется, и срок аренды совпадает с выданным при захвате
+func finishJob(ctx context.Context, tx *sql.Tx, job models.EnrichmentJob, status, lastError string) error {
+	query := `
+		UPDATE enrichment_jobs
+		SET status = $3, last_error = NULLIF($4, ''), locked_until = NULL, updated_at = now()
+		WHERE id = $1 AND status = 'running' AND locked_until = $2
+	`
+
+	result, err := tx.ExecContext(ctx, query, job.ID, job.LockedUntil, status, lastError)
+	if err != nil {
+		log.Printf("Error finishing enrichment job: %v", err)
+		return err
+	}
+
+	return checkJobOwned(result)
+}
+
+// checkJobOwned возвращает ErrJobLeaseLost, если запрос не изменил задачу
+func checkJobOwned(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrJobLeaseLost
+	}
+	return nil
+}
+
+// ListJobs возвращает задачи обогащения с фильтрацией по статусу и пагинацией
+func (r *EnrichmentRepository) ListJobs(ctx context.Context, status string, page, limit int) ([]models.EnrichmentJob, error) {
+	defer metrics.ObserveQuery("enrichment", "ListJobs", time.Now())
+
+	query := `SELECT ` + jobColumns + `
+              FROM enrichment_jobs j
+              JOIN songs s ON s.id = j.song_id`
+
+	var args []interface{}
+	if status != "" {
+		query += fmt.Sprintf(" WHERE j.status = $%d", len(args)+1)
+		args = append(args, status)
+	}
+
+	query += " ORDER BY j.id DESC"
+
+	query += " LIMIT $" + fmt.Sprintf("%d", len(args)+1)
+	args = append(args, limit)
+
+	query += " OFFSET $" + fmt.Sprintf("%d", len(args)+1)
+	args = append(args, (page-1)*limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error querying enrichment jobs: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []models.EnrichmentJob{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			log.Printf("Error scanning enrichment job: %v", err)
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// RetryJob возвращает проваленную задачу в очередь со сбросом счетчика попыток
+func (r *EnrichmentRepository) RetryJob(ctx context.Context, jobID int64) error {
+	defer metrics.ObserveQuery("enrichment", "RetryJob", time.Now())
+
+	query := `
+		UPDATE enrichment_jobs
+		SET status = 'queued', attempts = 0, next_run_at = now(), updated_at = now()
+		WHERE id = $1 AND status = 'failed'
+		RETURNING song_id
+	`
+
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var songID int
+		err := tx.QueryRowContext(ctx, query, jobID).Scan(&songID)
+		if err == sql.ErrNoRows {
+			return models.NotFound("failed enrichment job %d not found", jobID)
+		}
+		if isUniqueViolation(err) {
+			return models.Conflict("song already has an active enrichment job")
+		}
+		if err != nil {
+			log.Printf("Error retrying enrichment job: %v", err)
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE songs SET enrichment_status = 'pending' WHERE id = $1`, songID)
+		return err
+	})
+}
+
+// EnqueueSong ставит песню в очередь обогащения; возвращает false, если активная задача уже есть
+func (r *EnrichmentRepository) EnqueueSong(ctx context.Context, songID int, overwrite bool) (bool, error) {
+	defer metrics.ObserveQuery("enrichment", "EnqueueSong", time.Now())
+
+	query := `
+		WITH queued AS (
+			INSERT INTO enrichment_jobs (song_id, overwrite)
+			SELECT id, $2 FROM songs WHERE id = $1
+			ON CONFLICT (song_id) WHERE status IN ('queued', 'running') DO NOTHING
+			RETURNING song_id
+		)
+		UPDATE songs SET enrichment_status = 'pending'
+		WHERE id IN (SELECT song_id FROM queued)
+	`
+
+	var queued int64
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1)`, songID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return models.NotFound("song %d not found", songID)
+		}
+
+		result, err := tx.ExecContext(ctx, query, songID, overwrite)
+		if err != nil {
+			return err
+		}
+		queued, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		log.Printf("Error enqueueing song for enrichment: %v", err)
+		return false, err
+	}
+
+	return queued > 0, nil
+}
+
+// enqueueSongsQuery ставит в очередь песни, отобранные подзапросом, у которых нет активной задачи
+const enqueueSongsQuery = `
+		WITH queued AS (
+			INSERT INTO enrichment_jobs (song_id, overwrite)
+			SELECT id, %s FROM (%s) candidates
+			ON CONFLICT (song_id) WHERE status IN ('queued', 'running') DO NOTHING
+			RETURNING song_id
+		)
+		UPDATE songs SET enrichment_status = 'pending'
+		WHERE id IN (SELECT song_id FROM queued)
+	`
+
+// EnqueueSongs ставит в очередь обогащения песни, подходящие под фильтр, и возвращает их количество
+func (r *EnrichmentRepository) EnqueueSongs(ctx context.Context, req models.EnrichRequest) (int64, error) {
+	defer metrics.ObserveQuery("enrichment", "EnqueueSongs", time.Now())
+
+	candidates := `SELECT s.id FROM songs s WHERE 1=1`
+
+	var args []interface{}
+	var conditions []string
+
+	if req.Group != "" {
+		conditions = append(conditions, fmt.Sprintf(`s."group" ILIKE $%d`, len(args)+1))
+		args = append(args, "%"+req.Group+"%")
+	}
+	if req.Song != "" {
+		conditions = append(conditions, fmt.Sprintf("s.song_name ILIKE $%d", len(args)+1))
+		args = append(args, "%"+req.Song+"%")
+	}
+	if req.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("s.enrichment_status = $%d", len(args)+1))
+		args = append(args, req.Status)
+	}
+	if req.IncompleteOnly {
+		conditions = append(conditions, incompleteSongCondition)
+	}
+
+	if len(conditions) > 0 {
+		candidates += " AND " + strings.Join(conditions, " AND ")
+	}
+
+	candidates += " ORDER BY s.id LIMIT $" + fmt.Sprintf("%d", len(args)+1)
+	args = append(args, req.Limit)
+
+	overwrite := fmt.Sprintf("$%d::boolean", len(args)+1)
+	args = append(args, req.Overwrite)
+
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(enqueueSongsQuery, overwrite, candidates), args...)
+	if err != nil {
+		log.Printf("Error enqueueing songs for enrichment: %v", err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// incompleteSongCondition песня без текста, ссылки или точной до дня даты релиза
+const incompleteSongCondition = `(COALESCE(s.text, '') = '' OR COALESCE(s.link, '') = '' OR s.release_date IS NULL
+                                  OR s.release_date_precision <> 'day')`
+
+// EnqueueStaleSongs ставит в очередь неполные песни, не обогащавшиеся дольше incompleteAfter,
+// и прочие песни, не обогащавшиеся дольше staleAfter; недавно обработанные песни пропускаются
+func (r *EnrichmentRepository) EnqueueStaleSongs(ctx context.Context, staleAfter, incompleteAfter time.Duration, overwrite bool, limit int) (int64, error) {
+	defer metrics.ObserveQuery("enrichment", "EnqueueStaleSongs", time.Now())
+
+	candidates := `
+		SELECT s.id FROM songs s
+		WHERE NOT EXISTS (
+			SELECT 1 FROM enrichment_jobs j
+			WHERE j.song_id = s.id
+			  AND (j.status IN ('queued', 'running') OR j.updated_at > now() - make_interval(secs => $2))
+		)
+		AND (
+			(` + incompleteSongCondition + ` AND COALESCE(s.enriched_at, '-infinity') < now() - make_interval(secs => $2))
+			OR COALESCE(s.enriched_at, '-infinity') < now() - make_interval(secs => $1)
+		)
+		ORDER BY s.enriched_at NULLS FIRST, s.id
+		LIMIT $3`
+
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(enqueueSongsQuery, "$4::boolean", candidates),
+		staleAfter.Seconds(), incompleteAfter.Seconds(), limit, overwrite)
+	if err != nil {
+		log.Printf("Error enqueueing stale songs: %v", err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// RetryFailedJobs возвращает в очередь все проваленные задачи и возвращает их количество
+func (r *EnrichmentRepository) RetryFailedJobs(ctx context.Context) (int64, error) {
+	defer metrics.ObserveQuery("enrichment", "RetryFailedJobs", time.Now())
+
+	query := `
+		WITH retried AS (
+			UPDATE enrichment_jobs
+			SET status = 'queued', attempts = 0, next_run_at = now(), updated_at = now()
+			WHERE id IN (
+				SELECT DISTINCT ON (song_id) id FROM enrichment_jobs
+				WHERE status = 'failed'
+				ORDER BY song_id, id DESC
+			  )
+			  AND NOT EXISTS (
+				SELECT 1 FROM enrichment_jobs a
+				WHERE a.song_id = enrichment_jobs.song_id AND a.status IN ('queued', 'running')
+			  )
+			RETURNING song_id
+		)
+		UPDATE songs SET enrichment_status = 'pending'
+		WHERE id IN (SELECT song_id FROM retried)
+	`
+
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		log.Printf("Error retrying failed enrichment jobs: %v", err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"music-library/internal/metrics"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// migrationLockID ключ рекомендательной блокировки, под которой применяются миграции,
+// чтобы параллельно запущенные экземпляры не выполняли их одновременно
+const migrationLockID = 7_346_291_004
+
+// MigrationRepository применяет SQL-миграции и сообщает их состояние. Примененные
+// версии хранятся в таблице schema_migrations
+type MigrationRepository struct {
+	db    *sql.DB
+	files fs.FS
+}
+
+// NewMigrationRepository создает репозиторий миграций из файлов *.sql в корне files
+func NewMigrationRepository(db *sql.DB, files fs.FS) *MigrationRepository {
+	return &MigrationRepository{db: db, files: files}
+}
+
+// Versions возвращает версии всех известных миграций по порядку
+func (r *MigrationRepository) Versions() ([]string, error) {
+	names, err := fs.Glob(r.files, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(names))
+	for _, name := range names {
+		versions = append(versions, strings.TrimSuffix(name, path.Ext(name)))
+	}
+	slices.Sort(versions)
+	return versions, nil
+}
+
+// Applied возвращает примененные версии; до первого запуска миграций список пуст
+func (r *MigrationRepository) Applied(ctx context.Context) ([]string, error) {
+	defer metrics.ObserveQuery("migrations", "Applied", time.Now())
+
+	rows, err := r.db.QueryContext(ctx, `SELECT version FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "42P01" {
+			return nil, nil
+		}
+		log.Printf("Error querying applied migrations: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []string
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
+// Pending возвращает версии, которые еще не применены
+func (r *MigrationRepository) Pending(ctx context.Context) ([]string, error) {
+	versions, err := r.Versions()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := r.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []string{}
+	for _, version := range versions {
+		if !slices.Contains(applied, version) {
+			pending = append(pending, version)
+		}
+	}
+	return pending, nil
+}
+
+// Apply применяет недостающие миграции по порядку, каждую в своей транзакции,
+// и возвращает примененные версии
+func (r *MigrationRepository) Apply(ctx context.Context) ([]string, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationLockID); err != nil {
+			log.Printf("Error releasing migration lock: %v", err)
+		}
+	}()
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version VARCHAR(255) PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	// Список вычисляется под блокировкой, чтобы не применить миграцию дважды
+	pending, err := r.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []string
+	for _, version := range pending {
+		script, err := fs.ReadFile(r.files, version+".sql")
+		if err != nil {
+			return applied, err
+		}
+
+		if err := r.applyOne(ctx, conn, version, string(script)); err != nil {
+			return applied, fmt.Errorf("failed to apply migration %s: %w", version, err)
+		}
+		log.Printf("Applied migration %s", version)
+		applied = append(applied, version)
+	}
+	return applied, nil
+}
+
+// applyOne выполняет скрипт миграции и отмечает версию примененной в одной транзакции
+func (r *MigrationRepository) applyOne(ctx context.Context, conn *sql.Conn, version, script string) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// querier общий интерфейс для *sql.DB и *sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// loadProvenance возвращает происхождение полей для набора песен
+func loadProvenance(ctx context.Context, q querier, songIDs []int) (map[int]map[string]models.FieldProvenance, error) {
+	result := make(map[int]map[string]models.FieldProvenance)
+	if len(songIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT song_id, field, source, fetched_at, locked
+		FROM song_field_provenance
+		WHERE song_id = ANY($1)
+	`
+
+	rows, err := q.QueryContext(ctx, query, pq.Array(songIDs))
+	if err != nil {
+		log.Printf("Error querying field provenance: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var songID int
+		var field string
+		var prov models.FieldProvenance
+		if err := rows.Scan(&songID, &field, &prov.Source, &prov.FetchedAt, &prov.Locked); err != nil {
+			log.Printf("Error scanning field provenance: %v", err)
+			return nil, err
+		}
+		if result[songID] == nil {
+			result[songID] = make(map[string]models.FieldProvenance)
+		}
+		result[songID][field] = prov
+	}
+
+	return result, rows.Err()
+}
+
+// attachProvenance заполняет происхождение полей у списка песен
+func attachProvenance(ctx context.Context, q querier, songs []models.Song) error {
+	ids := make([]int, len(songs))
+	for i := range songs {
+		ids[i] = songs[i].ID
+	}
+
+	provenance, err := loadProvenance(ctx, q, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range songs {
+		songs[i].Provenance = provenance[songs[i].ID]
+	}
+	return nil
+}
+
+// attachSongProvenance заполняет происхождение полей одной песни
+func attachSongProvenance(ctx context.Context, q querier, song *models.Song) error {
+	provenance, err := loadProvenance(ctx, q, []int{song.ID})
+	if err != nil {
+		return err
+	}
+
+	song.Provenance = provenance[song.ID]
+	return nil
+}
+
+// recordProvenance сохраняет происхождение полей, значения которых изменились;
+// before == nil означает новую песню, и тогда учитываются все непустые поля.
+// Источник поля берется из sources, а при его отсутствии — из defaultSource
+func recordProvenance(ctx context.Context, tx *sql.Tx, before, after *models.Song, sources map[string]models.FieldProvenance, defaultSource string) error {
+	query := `
+		INSERT INTO song_field_provenance (song_id, field, source, fetched_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (song_id, field) DO UPDATE
+		SET source = EXCLUDED.source, fetched_at = EXCLUDED.fetched_at
+	`
+
+	if defaultSource == "" {
+		defaultSource = models.SourceManual
+	}
+
+	for _, field := range models.SongFields {
+		value := after.FieldValue(field)
+		if before == nil && value == "" {
+			continue
+		}
+		if before != nil && before.FieldValue(field) == value {
+			continue
+		}
+
+		prov, ok := sources[field]
+		if !ok || prov.Source == "" {
+			prov = models.FieldProvenance{Source: defaultSource, FetchedAt: time.Now()}
+		}
+
+		if _, err := tx.ExecContext(ctx, query, after.ID, field, prov.Source, prov.FetchedAt); err != nil {
+			log.Printf("Error recording field provenance: %v", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetFieldLocks блокирует или разблокирует поля песни от перезаписи обогащением
+func (r *SongRepository) SetFieldLocks(ctx context.Context, songID int, fields []string, locked bool, meta models.AuditMeta) (*models.Song, error) {
+	defer metrics.ObserveQuery("songs", "SetFieldLocks", time.Now())
+
+	query := `
+		INSERT INTO song_field_provenance (song_id, field, source, locked)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (song_id, field) DO UPDATE
+		SET locked = EXCLUDED.locked
+	`
+
+	var after *models.Song
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		before, err := getSongForUpdate(ctx, tx, songID)
+		if err != nil {
+			return err
+		}
+		if err := attachSongProvenance(ctx, tx, before); err != nil {
+			return err
+		}
+
+		for _, field := range fields {
+			if _, err := tx.ExecContext(ctx, query, songID, field, models.SourceUnknown, locked); err != nil {
+				log.Printf("Error updating field lock: %v", err)
+				return err
+			}
+		}
+
+		snapshot := *before
+		after = &snapshot
+		if err := attachSongProvenance(ctx, tx, after); err != nil {
+			return err
+		}
+
+		return insertAuditEntry(ctx, tx, models.AuditActionLock, songID, meta, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
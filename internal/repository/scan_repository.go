@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"time"
+)
+
+type ScanRepository struct {
+	db *sql.DB
+}
+
+// NewScanRepository создает новый экземпляр репозитория просканированных файлов
+func NewScanRepository(db *sql.DB) *ScanRepository {
+	return &ScanRepository{db: db}
+}
+
+// GetScannedFiles возвращает сведения о ранее просканированных файлах внутри каталога
+func (r *ScanRepository) GetScannedFiles(ctx context.Context, root string) (map[string]models.ScannedFile, error) {
+	defer metrics.ObserveQuery("scan", "GetScannedFiles", time.Now())
+
+	query := `
+		SELECT path, mod_time, size, COALESCE(song_id, 0)
+		FROM scanned_files
+		WHERE starts_with(path, $1)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, root)
+	if err != nil {
+		log.Printf("Error querying scanned files: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	files := make(map[string]models.ScannedFile)
+	for rows.Next() {
+		var file models.ScannedFile
+		if err := rows.Scan(&file.Path, &file.ModTime, &file.Size, &file.SongID); err != nil {
+			log.Printf("Error scanning file row: %v", err)
+			return nil, err
+		}
+		files[file.Path] = file
+	}
+
+	return files, rows.Err()
+}
+
+// SaveScannedFile сохраняет состояние файла после обработки
+func (r *ScanRepository) SaveScannedFile(ctx context.Context, file models.ScannedFile) error {
+	defer metrics.ObserveQuery("scan", "SaveScannedFile", time.Now())
+
+	query := `
+		INSERT INTO scanned_files (path, mod_time, size, song_id, scanned_at)
+		VALUES ($1, $2, $3, NULLIF($4, 0), now())
+		ON CONFLICT (path) DO UPDATE
+		SET mod_time = EXCLUDED.mod_time, size = EXCLUDED.size,
+		    song_id = EXCLUDED.song_id, scanned_at = EXCLUDED.scanned_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, file.Path, file.ModTime, file.Size, file.SongID); err != nil {
+		log.Printf("Error saving scanned file: %v", err)
+		return err
+	}
+
+	return nil
+}
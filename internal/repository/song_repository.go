@@ -1,15 +1,17 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
-	"errors"
 	"fmt"
 	"log"
 	"music-library/internal/config"
+	"music-library/internal/metrics"
 	"music-library/internal/models"
 	"strings"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type SongRepository struct {
@@ -20,8 +22,8 @@ type SongRepository struct {
 func InitPostgresDB(cfg *config.Config) (*sql.DB, error) {
 	// Формирование строки подключения с параметрами из конфигурации
 	connStr := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName,
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBSSLMode,
 	)
 
 	// Открытие соединения с базой данных
@@ -31,6 +33,11 @@ func InitPostgresDB(cfg *config.Config) (*sql.DB, error) {
 		return nil, err
 	}
 
+	// Ограничения пула соединений
+	db.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	db.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	db.SetConnMaxLifetime(time.Duration(cfg.DBConnMaxLifetime) * time.Second)
+
 	// Проверка соединения с базой данных
 	if err = db.Ping(); err != nil {
 		log.Printf("Error pinging database: %v", err)
@@ -41,15 +48,24 @@ func InitPostgresDB(cfg *config.Config) (*sql.DB, error) {
 	return db, nil
 }
 
+// releaseDateEnd первый день после периода даты релиза с учетом ее точности
+const releaseDateEnd = `(release_date + CASE release_date_precision
+                            WHEN 'year' THEN INTERVAL '1 year'
+                            WHEN 'month' THEN INTERVAL '1 month'
+                            ELSE INTERVAL '1 day' END)::date`
+
+// releaseDatePrecisionRank порядок точностей при сортировке: от точной к неполной
+const releaseDatePrecisionRank = `CASE release_date_precision WHEN 'day' THEN 0 WHEN 'month' THEN 1 ELSE 2 END`
+
 // NewSongRepository создает новый экземпляр репозитория песен
 func NewSongRepository(db *sql.DB) *SongRepository {
 	return &SongRepository{db: db}
 }
 
-// GetSongs возвращает список песен с фильтрацией и пагинацией
-func (r *SongRepository) GetSongs(group string, page, limit int) ([]models.Song, error) {
-	// Базовый запрос с динамическим условием фильтрации по группе
-	query := `SELECT id, "group", song_name, release_date, text, link 
+// buildSongQuery формирует запрос выборки песен с условиями фильтрации
+func buildSongQuery(filter models.SongFilter) (string, []interface{}) {
+	// Базовый запрос с динамическим условием фильтрации
+	query := `SELECT ` + songColumns + `
               FROM songs 
               WHERE 1=1`
 
@@ -58,9 +74,25 @@ func (r *SongRepository) GetSongs(group string, page, limit int) ([]models.Song,
 	var conditions []string
 
 	// Добавление фильтра по группе, если указана
-	if group != "" {
-		conditions = append(conditions, fmt.Sprintf("group ILIKE $%d", len(args)+1))
-		args = append(args, "%"+group+"%")
+	if filter.Group != "" {
+		conditions = append(conditions, fmt.Sprintf(`"group" ILIKE $%d`, len(args)+1))
+		args = append(args, "%"+filter.Group+"%")
+	}
+
+	// Добавление фильтра по названию песни, если указано
+	if filter.SongName != "" {
+		conditions = append(conditions, fmt.Sprintf("song_name ILIKE $%d", len(args)+1))
+		args = append(args, "%"+filter.SongName+"%")
+	}
+
+	// Диапазон дат релиза: период песни с учетом точности должен целиком лежать в диапазоне
+	if !filter.ReleasedFrom.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("release_date >= $%d", len(args)+1))
+		args = append(args, filter.ReleasedFrom.Start().Format("2006-01-02"))
+	}
+	if !filter.ReleasedTo.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("%s <= $%d", releaseDateEnd, len(args)+1))
+		args = append(args, filter.ReleasedTo.End().Format("2006-01-02"))
 	}
 
 	// Добавление условий к запросу
@@ -68,6 +100,25 @@ func (r *SongRepository) GetSongs(group string, page, limit int) ([]models.Song,
 		query += " AND " + strings.Join(conditions, " AND ")
 	}
 
+	// При равном начале периода точная дата идет раньше неполной; неизвестные даты — в конце
+	switch filter.Sort {
+	case models.SortByReleaseDate:
+		query += " ORDER BY release_date ASC NULLS LAST, " + releaseDatePrecisionRank + " ASC, id"
+	case models.SortByReleaseDateDesc:
+		query += " ORDER BY release_date DESC NULLS LAST, " + releaseDatePrecisionRank + " ASC, id"
+	default:
+		query += " ORDER BY id"
+	}
+
+	return query, args
+}
+
+// GetSongs возвращает список песен с фильтрацией и пагинацией
+func (r *SongRepository) GetSongs(ctx context.Context, filter models.SongFilter, page, limit int) ([]models.Song, error) {
+	defer metrics.ObserveQuery("songs", "GetSongs", time.Now())
+
+	query, args := buildSongQuery(filter)
+
 	// Добавление пагинации
 	query += " LIMIT $" + fmt.Sprintf("%d", len(args)+1)
 	args = append(args, limit)
@@ -76,7 +127,7 @@ func (r *SongRepository) GetSongs(group string, page, limit int) ([]models.Song,
 	args = append(args, (page-1)*limit)
 
 	// Выполнение запроса
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		log.Printf("Error querying songs: %v", err)
 		return nil, err
@@ -85,11 +136,7 @@ func (r *SongRepository) GetSongs(group string, page, limit int) ([]models.Song,
 
 	var songs []models.Song
 	for rows.Next() {
-		var song models.Song
-		err := rows.Scan(
-			&song.ID, &song.Group, &song.SongName,
-			&song.ReleaseDate, &song.Text, &song.Link,
-		)
+		song, err := scanSong(rows)
 		if err != nil {
 			log.Printf("Error scanning song row: %v", err)
 			return nil, err
@@ -97,102 +144,473 @@ func (r *SongRepository) GetSongs(group string, page, limit int) ([]models.Song,
 		songs = append(songs, song)
 	}
 
+	if err := attachProvenance(ctx, r.db, songs); err != nil {
+		return nil, err
+	}
+
 	return songs, nil
 }
 
-// CreateSong добавляет новую песню в базу данных
-func (r *SongRepository) CreateSong(song *models.Song) (*models.Song, error) {
-	query := `
-		INSERT INTO songs (group, song_name, release_date, text, link)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id
-	`
+// StreamSongs передает песни, подходящие под фильтр, в fn по одной, не накапливая их в памяти
+func (r *SongRepository) StreamSongs(ctx context.Context, filter models.SongFilter, fn func(models.Song) error) error {
+	defer metrics.ObserveQuery("songs", "StreamSongs", time.Now())
 
-	err := r.db.QueryRow(
-		query,
+	query, args := buildSongQuery(filter)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error querying songs: %v", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		song, err := scanSong(rows)
+		if err != nil {
+			log.Printf("Error scanning song row: %v", err)
+			return err
+		}
+		if err := fn(song); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// songColumns список колонок песни в порядке, ожидаемом scanSong
+const songColumns = `id, "group", song_name, COALESCE(to_char(release_date, 'YYYY-MM-DD'), ''),
+                     COALESCE(release_date_precision, ''),
+                     COALESCE(text, ''), COALESCE(link, ''), enrichment_status`
+
+// rowScanner общий интерфейс для *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSong читает песню из строки результата запроса
+func scanSong(row rowScanner) (models.Song, error) {
+	var song models.Song
+	var releaseDate, precision string
+	err := row.Scan(
+		&song.ID, &song.Group, &song.SongName,
+		&releaseDate, &precision, &song.Text, &song.Link,
+		&song.EnrichmentStatus,
+	)
+	if err != nil || releaseDate == "" {
+		return song, err
+	}
+
+	date, err := time.Parse("2006-01-02", releaseDate)
+	if err != nil {
+		return song, fmt.Errorf("invalid release date %q of song %d: %w", releaseDate, song.ID, err)
+	}
+	song.ReleaseDate = models.NewReleaseDate(date, models.DatePrecision(precision))
+	return song, nil
+}
+
+// releaseDateArgs возвращает значения столбцов release_date и release_date_precision;
+// неизвестная дата записывается как NULL в обоих
+func releaseDateArgs(date models.ReleaseDate) (interface{}, interface{}) {
+	if date.IsZero() {
+		return nil, nil
+	}
+	return date.Start().Format("2006-01-02"), string(date.Precision())
+}
+
+// getSongForUpdate читает песню в транзакции с блокировкой строки
+func getSongForUpdate(ctx context.Context, tx *sql.Tx, songID int) (*models.Song, error) {
+	query := `SELECT ` + songColumns + ` FROM songs WHERE id = $1 FOR UPDATE`
+
+	song, err := scanSong(tx.QueryRowContext(ctx, query, songID))
+	if err == sql.ErrNoRows {
+		return nil, models.NotFound("song %d not found", songID)
+	}
+	if err != nil {
+		log.Printf("Error fetching song: %v", err)
+		return nil, err
+	}
+
+	return &song, nil
+}
+
+// getSongsForUpdate получает и блокирует набор песен в порядке id; отсутствующих
+// песен в результате нет
+func getSongsForUpdate(ctx context.Context, tx *sql.Tx, songIDs []int) (map[int]*models.Song, error) {
+	query := `SELECT ` + songColumns + ` FROM songs WHERE id = ANY($1) ORDER BY id FOR UPDATE`
+
+	rows, err := tx.QueryContext(ctx, query, pq.Array(songIDs))
+	if err != nil {
+		log.Printf("Error fetching songs: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	songs := make(map[int]*models.Song, len(songIDs))
+	for rows.Next() {
+		song, err := scanSong(rows)
+		if err != nil {
+			log.Printf("Error scanning song: %v", err)
+			return nil, err
+		}
+		songs[song.ID] = &song
+	}
+
+	return songs, rows.Err()
+}
+
+// insertSongQuery добавляет песню; статус обогащения по умолчанию "done"
+const insertSongQuery = `
+		INSERT INTO songs ("group", song_name, release_date, release_date_precision, text, link, enrichment_status)
+		VALUES ($1, $2, $3::date, $4, $5, $6, COALESCE(NULLIF($7, ''), 'done'))
+		RETURNING ` + songColumns
+
+// insertSong добавляет песню в транзакции, ставит ее в очередь обогащения при статусе
+// "pending" и фиксирует событие в журнале аудита
+func insertSong(ctx context.Context, tx *sql.Tx, stmt *sql.Stmt, song *models.Song, meta models.AuditMeta) error {
+	releaseDate, precision := releaseDateArgs(song.ReleaseDate)
+	created, err := scanSong(stmt.QueryRowContext(ctx,
 		song.Group,
 		song.SongName,
-		song.ReleaseDate,
+		releaseDate,
+		precision,
 		song.Text,
 		song.Link,
-	).Scan(&song.ID)
-
+		song.EnrichmentStatus,
+	))
 	if err != nil {
 		log.Printf("Error creating song: %v", err)
+		return err
+	}
+
+	*song = created
+	if song.EnrichmentStatus == models.EnrichmentPending {
+		if err := enqueueEnrichmentJob(ctx, tx, song.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := recordProvenance(ctx, tx, nil, song, nil, meta.Source); err != nil {
+		return err
+	}
+
+	return insertAuditEntry(ctx, tx, models.AuditActionCreate, song.ID, meta, nil, song)
+}
+
+// CreateSong добавляет новую песню в базу данных и фиксирует событие в журнале аудита
+func (r *SongRepository) CreateSong(ctx context.Context, song *models.Song, meta models.AuditMeta) (*models.Song, error) {
+	defer metrics.ObserveQuery("songs", "CreateSong", time.Now())
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, insertSongQuery)
+		if err != nil {
+			log.Printf("Error preparing song insert: %v", err)
+			return err
+		}
+		defer stmt.Close()
+
+		if err := insertSong(ctx, tx, stmt, song, meta); err != nil {
+			return err
+		}
+		return attachSongProvenance(ctx, tx, song)
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	metrics.SongsCreated(songSource(meta), 1)
 	return song, nil
 }
 
-// UpdateSong обновляет информацию о песне
-func (r *SongRepository) UpdateSong(song *models.Song) error {
+// UpdateSong обновляет информацию о песне и фиксирует снимки до и после изменения
+func (r *SongRepository) UpdateSong(ctx context.Context, song *models.Song, meta models.AuditMeta) error {
+	defer metrics.ObserveQuery("songs", "UpdateSong", time.Now())
+
 	query := `
 		UPDATE songs 
-		SET group = $1, song_name = $2, 
-		    release_date = $3, text = $4, link = $5
-		WHERE id = $6
-	`
+		SET "group" = $1, song_name = $2, 
+		    release_date = $3::date, release_date_precision = $4, text = $5, link = $6
+		WHERE id = $7
+		RETURNING ` + songColumns
 
-	result, err := r.db.Exec(
-		query,
-		song.Group,
-		song.SongName,
-		song.ReleaseDate,
-		song.Text,
-		song.Link,
-		song.ID,
-	)
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		before, err := getSongForUpdate(ctx, tx, song.ID)
+		if err != nil {
+			return err
+		}
+
+		releaseDate, precision := releaseDateArgs(song.ReleaseDate)
+		after, err := scanSong(tx.QueryRowContext(ctx,
+			query,
+			song.Group,
+			song.SongName,
+			releaseDate,
+			precision,
+			song.Text,
+			song.Link,
+			song.ID,
+		))
+		if err != nil {
+			log.Printf("Error updating song: %v", err)
+			return err
+		}
+
+		if err := recordProvenance(ctx, tx, before, &after, nil, meta.Source); err != nil {
+			return err
+		}
+
+		return insertAuditEntry(ctx, tx, models.AuditActionUpdate, song.ID, meta, before, &after)
+	})
+}
+
+// DeleteSong удаляет песню по идентификатору и сохраняет ее последний снимок в журнале
+func (r *SongRepository) DeleteSong(ctx context.Context, songID int, meta models.AuditMeta) error {
+	defer metrics.ObserveQuery("songs", "DeleteSong", time.Now())
+
+	query := `DELETE FROM songs WHERE id = $1`
+
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		before, err := getSongForUpdate(ctx, tx, songID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, query, songID); err != nil {
+			log.Printf("Error deleting song: %v", err)
+			return err
+		}
+
+		return insertAuditEntry(ctx, tx, models.AuditActionDelete, songID, meta, before, nil)
+	})
+}
 
+// GetSongByID возвращает песню по идентификатору
+func (r *SongRepository) GetSongByID(ctx context.Context, songID int) (*models.Song, error) {
+	defer metrics.ObserveQuery("songs", "GetSongByID", time.Now())
+
+	query := `SELECT ` + songColumns + ` FROM songs WHERE id = $1`
+
+	song, err := scanSong(r.db.QueryRowContext(ctx, query, songID))
+	if err == sql.ErrNoRows {
+		return nil, models.NotFound("song %d not found", songID)
+	}
 	if err != nil {
-		log.Printf("Error updating song: %v", err)
-		return err
+		log.Printf("Error fetching song: %v", err)
+		return nil, err
+	}
+
+	if err := attachSongProvenance(ctx, r.db, &song); err != nil {
+		return nil, err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	return &song, nil
+}
+
+// FindSongByKey ищет песню по группе и названию без учета регистра, nil означает отсутствие
+func (r *SongRepository) FindSongByKey(ctx context.Context, group, songName string) (*models.Song, error) {
+	defer metrics.ObserveQuery("songs", "FindSongByKey", time.Now())
+
+	query := `SELECT ` + songColumns + `
+              FROM songs
+              WHERE lower("group") = lower($1) AND lower(song_name) = lower($2)
+              ORDER BY id
+              LIMIT 1`
+
+	song, err := scanSong(r.db.QueryRowContext(ctx, query, group, songName))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
-		return err
+		log.Printf("Error finding song: %v", err)
+		return nil, err
 	}
 
-	if rowsAffected == 0 {
-		return errors.New("no song found with the given ID")
+	if err := attachSongProvenance(ctx, r.db, &song); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return &song, nil
 }
 
-// DeleteSong удаляет песню по идентификатору
-func (r *SongRepository) DeleteSong(songID int) error {
-	query := `DELETE FROM songs WHERE id = $1`
+// FindExistingKeys возвращает пары группа/название (в нижнем регистре), уже присутствующие в библиотеке
+func (r *SongRepository) FindExistingKeys(ctx context.Context, groups, names []string) (map[[2]string]bool, error) {
+	defer metrics.ObserveQuery("songs", "FindExistingKeys", time.Now())
 
-	result, err := r.db.Exec(query, songID)
+	query := `
+		SELECT DISTINCT lower(s."group"), lower(s.song_name)
+		FROM songs s
+		JOIN unnest($1::text[], $2::text[]) AS k(g, n)
+		  ON lower(s."group") = k.g AND lower(s.song_name) = k.n
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(groups), pq.Array(names))
 	if err != nil {
-		log.Printf("Error deleting song: %v", err)
-		return err
+		log.Printf("Error checking existing songs: %v", err)
+		return nil, err
 	}
+	defer rows.Close()
+
+	existing := make(map[[2]string]bool)
+	for rows.Next() {
+		var key [2]string
+		if err := rows.Scan(&key[0], &key[1]); err != nil {
+			log.Printf("Error scanning existing song key: %v", err)
+			return nil, err
+		}
+		existing[key] = true
+	}
+
+	return existing, rows.Err()
+}
+
+// CreateSongsBatch добавляет пачку песен в одной транзакции вместе с записями аудита
+func (r *SongRepository) CreateSongsBatch(ctx context.Context, songs []*models.Song, meta models.AuditMeta) error {
+	defer metrics.ObserveQuery("songs", "CreateSongsBatch", time.Now())
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, insertSongQuery)
+		if err != nil {
+			log.Printf("Error preparing batch insert: %v", err)
+			return err
+		}
+		defer stmt.Close()
 
-	rowsAffected, err := result.RowsAffected()
+		for _, song := range songs {
+			if err := insertSong(ctx, tx, stmt, song, meta); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return errors.New("no song found with the given ID")
+	metrics.SongsCreated(songSource(meta), len(songs))
+	return nil
+}
+
+// songSource источник добавления песен для метрик
+func songSource(meta models.AuditMeta) string {
+	if meta.Source == "" {
+		return models.SourceUnknown
 	}
+	return meta.Source
+}
 
-	return nil
+// songReferences таблицы и колонки, ссылающиеся на песни; при слиянии дубликатов
+// ссылки переносятся на сохраняемую песню
+var songReferences = []struct {
+	table  string
+	column string
+}{
+	{"scanned_files", "song_id"},
+}
+
+// MergeSongs объединяет дубликаты в песню keepID в одной транзакции: значения полей
+// выбирает pick, ссылки переносятся на keepID, дубликаты удаляются
+func (r *SongRepository) MergeSongs(ctx context.Context, keepID int, duplicateIDs []int, meta models.AuditMeta, pick func(keep models.Song, duplicates []models.Song) models.Song) (*models.Song, error) {
+	defer metrics.ObserveQuery("songs", "MergeSongs", time.Now())
+
+	updateQuery := `
+		UPDATE songs
+		SET "group" = $1, song_name = $2,
+		    release_date = $3::date, release_date_precision = $4, text = $5, link = $6
+		WHERE id = $7
+		RETURNING ` + songColumns
+
+	var merged models.Song
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		// Все строки блокируются одним запросом в порядке id: при слиянии пересекающихся
+		// наборов в разном порядке транзакции не ждут друг друга по кругу
+		locked, err := getSongsForUpdate(ctx, tx, append([]int{keepID}, duplicateIDs...))
+		if err != nil {
+			return err
+		}
+
+		keep, ok := locked[keepID]
+		if !ok {
+			return models.NotFound("song %d not found", keepID)
+		}
+
+		duplicates := make([]models.Song, 0, len(duplicateIDs))
+		for _, id := range duplicateIDs {
+			duplicate, ok := locked[id]
+			if !ok {
+				return models.NotFound("song %d not found", id)
+			}
+			duplicates = append(duplicates, *duplicate)
+		}
+
+		result := pick(*keep, duplicates)
+		releaseDate, precision := releaseDateArgs(result.ReleaseDate)
+		merged, err = scanSong(tx.QueryRowContext(ctx,
+			updateQuery,
+			result.Group,
+			result.SongName,
+			releaseDate,
+			precision,
+			result.Text,
+			result.Link,
+			keepID,
+		))
+		if err != nil {
+			log.Printf("Error updating merged song: %v", err)
+			return err
+		}
+
+		// Перенос ссылок с дубликатов на сохраняемую песню
+		for _, ref := range songReferences {
+			query := fmt.Sprintf(`UPDATE %s SET %s = $1 WHERE %s = ANY($2)`, ref.table, ref.column, ref.column)
+			if _, err := tx.ExecContext(ctx, query, keepID, pq.Array(duplicateIDs)); err != nil {
+				log.Printf("Error re-pointing %s references: %v", ref.table, err)
+				return err
+			}
+		}
+
+		if err := recordProvenance(ctx, tx, keep, &merged, nil, meta.Source); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM songs WHERE id = ANY($1)`, pq.Array(duplicateIDs)); err != nil {
+			log.Printf("Error deleting merged duplicates: %v", err)
+			return err
+		}
+
+		if err := insertAuditEntry(ctx, tx, models.AuditActionUpdate, keepID, meta, keep, &merged); err != nil {
+			return err
+		}
+		for i := range duplicates {
+			if err := insertAuditEntry(ctx, tx, models.AuditActionMerge, duplicates[i].ID, meta, &duplicates[i], &merged); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &merged, nil
 }
 
 // GetSongText получает текст песни постранично
-func (r *SongRepository) GetSongText(songID, page, limit int) (string, error) {
+func (r *SongRepository) GetSongText(ctx context.Context, songID, page, limit int) (string, error) {
+	defer metrics.ObserveQuery("songs", "GetSongText", time.Now())
+
 	query := `
-		SELECT text 
-		FROM songs 
+		SELECT COALESCE(text, '')
+		FROM songs
 		WHERE id = $1
 	`
 
 	var fullText string
-	err := r.db.QueryRow(query, songID).Scan(&fullText)
+	err := r.db.QueryRowContext(ctx, query, songID).Scan(&fullText)
+	if err == sql.ErrNoRows {
+		return "", models.NotFound("song %d not found", songID)
+	}
 	if err != nil {
 		log.Printf("Error fetching song text: %v", err)
 		return "", err
@@ -206,7 +624,7 @@ func (r *SongRepository) GetSongText(songID, page, limit int) (string, error) {
 	end := start + limit
 
 	if start >= len(verses) {
-		return "", errors.New("page out of range")
+		return "", models.NotFound("page %d is out of range", page)
 	}
 
 	if end > len(verses) {
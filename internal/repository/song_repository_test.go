@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"music-library/internal/models"
+)
+
+func TestBuildSongQueryReleaseRange(t *testing.T) {
+	tests := []struct {
+		from, to string
+		wantArgs []interface{}
+	}{
+		// Границы диапазона раскрываются в периоды: с начала from до конца to включительно
+		{"2006", "2006", []interface{}{"2006-01-01", "2007-01-01"}},
+		{"2006-12", "2007-02", []interface{}{"2006-12-01", "2007-03-01"}},
+		{"2004-02-29", "2004-02-29", []interface{}{"2004-02-29", "2004-03-01"}},
+		{"", "2006-12", []interface{}{"2007-01-01"}},
+		{"2006-07", "", []interface{}{"2006-07-01"}},
+	}
+
+	for _, tt := range tests {
+		from, _ := models.ParseReleaseDate(tt.from)
+		to, _ := models.ParseReleaseDate(tt.to)
+		query, args := buildSongQuery(models.SongFilter{ReleasedFrom: from, ReleasedTo: to})
+
+		if len(args) != len(tt.wantArgs) {
+			t.Fatalf("from %q to %q: args = %v, want %v", tt.from, tt.to, args, tt.wantArgs)
+		}
+		for i := range args {
+			if args[i] != tt.wantArgs[i] {
+				t.Errorf("from %q to %q: args = %v, want %v", tt.from, tt.to, args, tt.wantArgs)
+				break
+			}
+		}
+
+		// Конец периода песни сравнивается с исключающей границей: песня 2006 года
+		// попадает в диапазон по 2006-12 только целиком
+		if !to.IsZero() && !strings.Contains(query, releaseDateEnd+" <= $") {
+			t.Errorf("from %q to %q: query does not compare the end of the song period:\n%s", tt.from, tt.to, query)
+		}
+		if !from.IsZero() && !strings.Contains(query, "release_date >= $1") {
+			t.Errorf("from %q to %q: query does not compare the start of the song period:\n%s", tt.from, tt.to, query)
+		}
+	}
+}
+
+func TestBuildSongQuerySort(t *testing.T) {
+	tests := []struct {
+		sort string
+		want string
+	}{
+		{models.SortByReleaseDate, "ORDER BY release_date ASC NULLS LAST, " + releaseDatePrecisionRank + " ASC, id"},
+		{models.SortByReleaseDateDesc, "ORDER BY release_date DESC NULLS LAST, " + releaseDatePrecisionRank + " ASC, id"},
+		{"", "ORDER BY id"},
+	}
+
+	for _, tt := range tests {
+		query, _ := buildSongQuery(models.SongFilter{Sort: tt.sort})
+		if !strings.HasSuffix(query, tt.want) {
+			t.Errorf("sort %q: query ends with %q, want %q", tt.sort, query[strings.LastIndex(query, "ORDER BY"):], tt.want)
+		}
+	}
+}
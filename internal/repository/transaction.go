@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/lib/pq"
+)
+
+// isUniqueViolation сообщает, что запрос нарушил ограничение уникальности
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// withTx выполняет fn внутри транзакции и фиксирует ее при успехе
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			log.Printf("Error rolling back transaction: %v", rbErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// AuditService предоставляет доступ к журналу аудита изменяющих операций
+type AuditService struct {
+	repo *repository.AuditRepository
+}
+
+// NewAuditService создает новый экземпляр сервиса журнала аудита
+func NewAuditService(repo *repository.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// ListEntries возвращает записи журнала с фильтрацией и пагинацией
+func (s *AuditService) ListEntries(ctx context.Context, filter models.AuditFilter, page, limit int) ([]models.AuditEntry, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 500 {
+		limit = 50 // значение по умолчанию
+	}
+
+	entries, err := s.repo.ListEntries(ctx, filter, page, limit)
+	if err != nil {
+		log.Printf("Error in ListEntries: %v", err)
+		return nil, fmt.Errorf("failed to retrieve audit log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// ExportEntries передает все подходящие записи журнала в fn в порядке их создания
+func (s *AuditService) ExportEntries(ctx context.Context, filter models.AuditFilter, fn func(models.AuditEntry) error) error {
+	if err := s.repo.StreamEntries(ctx, filter, fn); err != nil {
+		log.Printf("Error exporting audit log: %v", err)
+		return fmt.Errorf("failed to export audit log: %w", err)
+	}
+
+	return nil
+}
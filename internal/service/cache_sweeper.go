@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"log"
+	"music-library/internal/config"
+	"music-library/internal/repository"
+	"sync"
+	"time"
+)
+
+// CacheSweeper периодически удаляет просроченные записи кэша ответов из базы данных:
+// чтение их пропускает, но сами они не исчезают
+type CacheSweeper struct {
+	repo     *repository.DetailsCacheRepository
+	interval time.Duration
+	wg       sync.WaitGroup
+}
+
+// NewCacheSweeper создает очистку кэша; она нужна только бэкендам, хранящим кэш в базе
+func NewCacheSweeper(repo *repository.DetailsCacheRepository, cfg *config.Config) *CacheSweeper {
+	interval := time.Duration(cfg.CacheSweepInterval) * time.Second
+	if cfg.CacheBackend != CacheBackendDB && cfg.CacheBackend != CacheBackendLayered {
+		interval = 0
+	}
+
+	return &CacheSweeper{repo: repo, interval: interval}
+}
+
+// Start запускает очистку, которая работает до отмены ctx; нулевой интервал отключает ее
+func (s *CacheSweeper) Start(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.sweep(ctx)
+			}
+		}
+	}()
+
+	log.Printf("Started details cache sweep every %s", s.interval)
+}
+
+// Wait ожидает остановки очистки
+func (s *CacheSweeper) Wait() {
+	s.wg.Wait()
+}
+
+// sweep удаляет просроченные записи; ошибка только пишется в лог
+func (s *CacheSweeper) sweep(ctx context.Context) {
+	count, err := s.repo.DeleteExpired(ctx)
+	if err != nil {
+		return
+	}
+
+	if count > 0 {
+		log.Printf("Deleted %d expired details cache entries", count)
+	}
+}
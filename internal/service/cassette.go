@@ -0,0 +1,169 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Режимы работы с кассетами внешнего API
+const (
+	CassetteOff    = "off"
+	CassetteRecord = "record"
+	CassetteReplay = "replay"
+)
+
+// ErrCassetteMismatch в режиме воспроизведения для запроса нет записанного ответа
+var ErrCassetteMismatch = errors.New("no recorded response in cassette")
+
+// cassette одна записанная пара запрос/ответ внешнего API
+type cassette struct {
+	Request    cassetteRequest  `json:"request"`
+	Response   cassetteResponse `json:"response"`
+	RecordedAt time.Time        `json:"recorded_at"`
+}
+
+type cassetteRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+type cassetteResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body"`
+}
+
+// CassetteTransport записывает ответы внешнего API в файлы кассет или отвечает из них
+// без обращения к сети. Запросы сопоставляются по методу, пути и параметрам без учета
+// хоста, поэтому записи, сделанные на одном стенде, воспроизводятся с любым API_BASE_URL
+type CassetteTransport struct {
+	mode string
+	dir  string
+	next http.RoundTripper
+}
+
+// NewCassetteTransport оборачивает транспорт в режиме record или replay
+func NewCassetteTransport(mode, dir string, next http.RoundTripper) (*CassetteTransport, error) {
+	if mode != CassetteRecord && mode != CassetteReplay {
+		return nil, fmt.Errorf("unknown cassette mode %q", mode)
+	}
+	if dir == "" {
+		return nil, fmt.Errorf("cassette directory is not set")
+	}
+
+	if mode == CassetteRecord {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create cassette directory: %w", err)
+		}
+	} else if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("cassette directory is not available: %w", err)
+	}
+
+	return &CassetteTransport{mode: mode, dir: dir, next: next}, nil
+}
+
+func (t *CassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.mode == CassetteReplay {
+		return t.replay(req)
+	}
+	return t.record(req)
+}
+
+// cassetteKey идентифицирует запрос без схемы и хоста
+func cassetteKey(req *http.Request) string {
+	return req.Method + " " + req.URL.RequestURI()
+}
+
+// path возвращает файл кассеты для запроса
+func (t *CassetteTransport) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(t.dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// replay отвечает из кассеты; запрос без записи попадает в лог и возвращается ошибкой,
+// которая сохраняется в last_error задачи обогащения
+func (t *CassetteTransport) replay(req *http.Request) (*http.Response, error) {
+	key := cassetteKey(req)
+
+	data, err := os.ReadFile(t.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		log.Printf("Cassette mismatch: %s is not recorded in %s", key, t.dir)
+		return nil, fmt.Errorf("%w: %s", ErrCassetteMismatch, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var rec cassette
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette for %s: %w", key, err)
+	}
+	if rec.Request.Method != req.Method || rec.Request.URL != req.URL.RequestURI() {
+		// Совпадение префикса хэша у разных запросов
+		log.Printf("Cassette mismatch: %s collides with recorded %s %s", key, rec.Request.Method, rec.Request.URL)
+		return nil, fmt.Errorf("%w: %s", ErrCassetteMismatch, key)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Response.Status, http.StatusText(rec.Response.Status)),
+		StatusCode:    rec.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rec.Response.Header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(rec.Response.Body))),
+		ContentLength: int64(len(rec.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// record выполняет запрос через сеть и сохраняет в кассету ответ, который не требует
+// повтора; ответы 429 и 5xx передаются дальше без записи
+func (t *CassetteTransport) record(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	key := cassetteKey(req)
+	if retryableStatus(resp.StatusCode) {
+		// Временный сбой не записывается, чтобы не затереть удачный ответ, записанный
+		// раньше или полученный с другого адреса API
+		log.Printf("Cassette skip: %s returned %d", key, resp.StatusCode)
+		return resp, nil
+	}
+
+	rec := cassette{
+		Request:    cassetteRequest{Method: req.Method, URL: req.URL.RequestURI()},
+		Response:   cassetteResponse{Status: resp.StatusCode, Header: resp.Header, Body: string(body)},
+		RecordedAt: time.Now().UTC(),
+	}
+
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err == nil {
+		err = os.WriteFile(t.path(key), append(data, '\n'), 0o644)
+	}
+	if err != nil {
+		// Ошибка записи кассеты не должна ломать сам запрос
+		log.Printf("Error recording cassette for %s: %v", key, err)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// Состояния автоматического выключателя
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// CircuitBreaker перестает пропускать запросы после серии сбоев и через паузу
+// пропускает одиночный пробный запрос, по результату которого замыкается снова
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	state     string
+	failures  int
+	threshold int
+	cooldown  time.Duration
+	openedAt  time.Time
+	probing   bool
+}
+
+// NewCircuitBreaker создает выключатель, размыкающийся после threshold сбоев подряд
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		state:     BreakerClosed,
+		threshold: max(threshold, 1),
+		cooldown:  cooldown,
+	}
+}
+
+// Allow сообщает, можно ли выполнить запрос сейчас
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		// Пауза истекла: пропускаем один пробный запрос
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+
+	return true
+}
+
+// Success фиксирует успешный запрос и замыкает выключатель
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure фиксирует сбой; неудачная проба или превышение порога размыкают выключатель
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+	b.probing = false
+}
+
+// State возвращает текущее состояние выключателя
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// Release освобождает право на пробный запрос, если запрос был отменен без результата
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"log"
+	"music-library/internal/config"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Бэкенды кэша ответов внешнего API
+const (
+	CacheBackendNone    = "none"
+	CacheBackendLRU     = "lru"
+	CacheBackendDB      = "db"
+	CacheBackendLayered = "layered"
+)
+
+// DetailsCache хранилище ответов внешнего API; Get возвращает nil при промахе
+// или истекшем сроке жизни записи
+type DetailsCache interface {
+	Get(ctx context.Context, key string) (*models.DetailsCacheEntry, error)
+	Set(ctx context.Context, entry *models.DetailsCacheEntry) error
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, limit int) ([]models.DetailsCacheEntry, error)
+	Purge(ctx context.Context) error
+}
+
+// NewDetailsCache создает кэш согласно CACHE_BACKEND; для "none" возвращает nil
+func NewDetailsCache(cfg *config.Config, repo *repository.DetailsCacheRepository) (DetailsCache, error) {
+	switch cfg.CacheBackend {
+	case CacheBackendNone:
+		return nil, nil
+	case CacheBackendLRU, "":
+		return NewLRUDetailsCache(cfg.CacheSize), nil
+	case CacheBackendDB:
+		return repo, nil
+	case CacheBackendLayered:
+		return &LayeredDetailsCache{front: NewLRUDetailsCache(cfg.CacheSize), back: repo}, nil
+	}
+	return nil, fmt.Errorf("unknown cache backend %q", cfg.CacheBackend)
+}
+
+// detailsCacheKey ключ кэша для пары группа/песня
+func detailsCacheKey(group, song string) string {
+	key := songKey(group, song)
+	return key[0] + "\x1f" + key[1]
+}
+
+// LRUDetailsCache кэш в памяти процесса, вытесняющий давно не использованные записи
+type LRUDetailsCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+// NewLRUDetailsCache создает LRU-кэш на capacity записей
+func NewLRUDetailsCache(capacity int) *LRUDetailsCache {
+	return &LRUDetailsCache{
+		capacity: max(capacity, 1),
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *LRUDetailsCache) Get(ctx context.Context, key string) (*models.DetailsCacheEntry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, nil
+	}
+
+	entry := elem.Value.(*models.DetailsCacheEntry)
+	if entry.Expired(time.Now()) {
+		c.order.Remove(elem)
+		delete(c.items, key)
+		return nil, nil
+	}
+
+	c.order.MoveToFront(elem)
+	return entry, nil
+}
+
+func (c *LRUDetailsCache) Set(ctx context.Context, entry *models.DetailsCacheEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[entry.Key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.items[entry.Key] = c.order.PushFront(entry)
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*models.DetailsCacheEntry).Key)
+	}
+
+	return nil
+}
+
+func (c *LRUDetailsCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.order.Remove(elem)
+		delete(c.items, key)
+	}
+	return nil
+}
+
+func (c *LRUDetailsCache) List(ctx context.Context, limit int) ([]models.DetailsCacheEntry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	var entries []models.DetailsCacheEntry
+	for elem := c.order.Front(); elem != nil && len(entries) < limit; elem = elem.Next() {
+		entry := elem.Value.(*models.DetailsCacheEntry)
+		if !entry.Expired(now) {
+			entries = append(entries, *entry)
+		}
+	}
+
+	return entries, nil
+}
+
+func (c *LRUDetailsCache) Purge(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+	return nil
+}
+
+// LayeredDetailsCache LRU-кэш в памяти поверх постоянного кэша в базе данных
+type LayeredDetailsCache struct {
+	front DetailsCache
+	back  DetailsCache
+}
+
+func (c *LayeredDetailsCache) Get(ctx context.Context, key string) (*models.DetailsCacheEntry, error) {
+	if entry, err := c.front.Get(ctx, key); err != nil || entry != nil {
+		return entry, err
+	}
+
+	entry, err := c.back.Get(ctx, key)
+	if err != nil || entry == nil {
+		return entry, err
+	}
+
+	// Поднимаем запись из базы в память
+	if err := c.front.Set(ctx, entry); err != nil {
+		log.Printf("Error warming in-memory details cache: %v", err)
+	}
+	return entry, nil
+}
+
+func (c *LayeredDetailsCache) Set(ctx context.Context, entry *models.DetailsCacheEntry) error {
+	if err := c.back.Set(ctx, entry); err != nil {
+		return err
+	}
+	return c.front.Set(ctx, entry)
+}
+
+func (c *LayeredDetailsCache) Delete(ctx context.Context, key string) error {
+	if err := c.back.Delete(ctx, key); err != nil {
+		return err
+	}
+	return c.front.Delete(ctx, key)
+}
+
+func (c *LayeredDetailsCache) List(ctx context.Context, limit int) ([]models.DetailsCacheEntry, error) {
+	return c.back.List(ctx, limit)
+}
+
+func (c *LayeredDetailsCache) Purge(ctx context.Context) error {
+	if err := c.back.Purge(ctx); err != nil {
+		return err
+	}
+	return c.front.Purge(ctx)
+}
+
+// CacheService управляет кэшем ответов внешнего API
+type CacheService struct {
+	cache DetailsCache
+}
+
+// NewCacheService создает новый экземпляр сервиса управления кэшем
+func NewCacheService(cache DetailsCache) *CacheService {
+	return &CacheService{cache: cache}
+}
+
+// errCacheDisabled кэш выключен настройкой CACHE_BACKEND=none
+var errCacheDisabled = models.Conflict("details cache is disabled")
+
+// ListEntries возвращает записи кэша
+func (s *CacheService) ListEntries(ctx context.Context, limit int) ([]models.DetailsCacheEntry, error) {
+	if s.cache == nil {
+		return nil, errCacheDisabled
+	}
+	if limit < 1 || limit > 1000 {
+		limit = 100 // значение по умолчанию
+	}
+
+	entries, err := s.cache.List(ctx, limit)
+	if err != nil {
+		log.Printf("Error listing details cache: %v", err)
+		return nil, fmt.Errorf("failed to list cache entries: %w", err)
+	}
+	if entries == nil {
+		entries = []models.DetailsCacheEntry{}
+	}
+
+	return entries, nil
+}
+
+// GetEntry возвращает запись кэша для пары группа/песня, nil означает отсутствие
+func (s *CacheService) GetEntry(ctx context.Context, group, song string) (*models.DetailsCacheEntry, error) {
+	if s.cache == nil {
+		return nil, errCacheDisabled
+	}
+
+	entry, err := s.cache.Get(ctx, detailsCacheKey(group, song))
+	if err != nil {
+		log.Printf("Error reading details cache: %v", err)
+		return nil, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// Invalidate удаляет запись кэша для пары группа/песня
+func (s *CacheService) Invalidate(ctx context.Context, group, song string) error {
+	if s.cache == nil {
+		return errCacheDisabled
+	}
+	if strings.TrimSpace(group) == "" || strings.TrimSpace(song) == "" {
+		return models.Validation("group and song name cannot be empty")
+	}
+
+	if err := s.cache.Delete(ctx, detailsCacheKey(group, song)); err != nil {
+		log.Printf("Error invalidating details cache: %v", err)
+		return fmt.Errorf("failed to invalidate cache entry: %w", err)
+	}
+
+	log.Printf("Invalidated details cache for %s by %s", song, group)
+	return nil
+}
+
+// Purge очищает кэш полностью
+func (s *CacheService) Purge(ctx context.Context) error {
+	if s.cache == nil {
+		return errCacheDisabled
+	}
+
+	if err := s.cache.Purge(ctx); err != nil {
+		log.Printf("Error purging details cache: %v", err)
+		return fmt.Errorf("failed to purge cache: %w", err)
+	}
+
+	log.Println("Purged details cache")
+	return nil
+}
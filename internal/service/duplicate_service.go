@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+	"sort"
+)
+
+const (
+	defaultDuplicateThreshold = 0.85
+	lyricDuplicateThreshold   = 0.8
+)
+
+// DuplicateService ищет и объединяет дубликаты песен
+type DuplicateService struct {
+	repo *repository.SongRepository
+}
+
+// NewDuplicateService создает новый экземпляр сервиса поиска дубликатов
+func NewDuplicateService(repo *repository.SongRepository) *DuplicateService {
+	return &DuplicateService{repo: repo}
+}
+
+// duplicateCandidate песня с заранее вычисленными ключами сравнения
+type duplicateCandidate struct {
+	song     models.Song
+	group    string
+	name     string
+	shingles map[string]struct{}
+}
+
+// unionFind система непересекающихся множеств для сборки кластеров
+type unionFind []int
+
+func newUnionFind(n int) unionFind {
+	uf := make(unionFind, n)
+	for i := range uf {
+		uf[i] = i
+	}
+	return uf
+}
+
+func (uf unionFind) find(i int) int {
+	for uf[i] != i {
+		uf[i] = uf[uf[i]]
+		i = uf[i]
+	}
+	return i
+}
+
+func (uf unionFind) union(a, b int) {
+	uf[uf.find(a)] = uf.find(b)
+}
+
+// FindDuplicates группирует вероятные дубликаты по нормализованным и нечетко совпадающим
+// группе и названию, а при opts.Lyrics также по сходству текстов
+func (s *DuplicateService) FindDuplicates(ctx context.Context, opts models.DuplicateOptions) ([]models.DuplicateCluster, error) {
+	if opts.Threshold <= 0 || opts.Threshold > 1 {
+		opts.Threshold = defaultDuplicateThreshold
+	}
+
+	var candidates []duplicateCandidate
+	err := s.repo.StreamSongs(ctx, models.SongFilter{Group: opts.Group}, func(song models.Song) error {
+		c := duplicateCandidate{
+			song:  song,
+			group: normalizeTitle(song.Group),
+			name:  normalizeTitle(song.SongName),
+		}
+		if opts.Lyrics {
+			c.shingles = lyricShingles(song.Text)
+		}
+		candidates = append(candidates, c)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error loading songs for duplicate search: %v", err)
+		return nil, fmt.Errorf("failed to load songs: %w", err)
+	}
+
+	blocks := groupBlocks(candidates, opts.Threshold)
+
+	// Попарное сравнение песен внутри блоков похожих групп
+	uf := newUnionFind(len(candidates))
+	type edge struct {
+		score  float64
+		reason string
+	}
+	edges := make(map[int][]edge)
+
+	for _, block := range blocks {
+		for i := 0; i < len(block); i++ {
+			for j := i + 1; j < len(block); j++ {
+				a, b := &candidates[block[i]], &candidates[block[j]]
+
+				score, reason := 0.0, ""
+				if a.name == b.name {
+					score, reason = 1, "normalized name match"
+				} else if sim := stringSimilarity(a.name, b.name); sim >= opts.Threshold {
+					score, reason = sim, "similar name"
+				} else if opts.Lyrics {
+					if sim := jaccard(a.shingles, b.shingles); sim >= lyricDuplicateThreshold {
+						score, reason = sim, "similar lyrics"
+					}
+				}
+				if reason == "" {
+					continue
+				}
+				if a.group != b.group {
+					reason += ", similar group"
+				}
+
+				uf.union(block[i], block[j])
+				edges[block[i]] = append(edges[block[i]], edge{score, reason})
+			}
+		}
+	}
+
+	// Сборка кластеров из связанных пар
+	members := make(map[int][]int)
+	for i := range candidates {
+		members[uf.find(i)] = append(members[uf.find(i)], i)
+	}
+
+	clusters := []models.DuplicateCluster{}
+	for _, idx := range members {
+		if len(idx) < 2 {
+			continue
+		}
+
+		cluster := models.DuplicateCluster{Score: 1}
+		reasons := make(map[string]bool)
+		for _, i := range idx {
+			cluster.Songs = append(cluster.Songs, candidates[i].song)
+			for _, e := range edges[i] {
+				// Оценка кластера определяется самой слабой связью
+				if e.score < cluster.Score {
+					cluster.Score = e.score
+				}
+				if !reasons[e.reason] {
+					reasons[e.reason] = true
+					cluster.Reasons = append(cluster.Reasons, e.reason)
+				}
+			}
+		}
+
+		sort.Slice(cluster.Songs, func(i, j int) bool { return cluster.Songs[i].ID < cluster.Songs[j].ID })
+		sort.Strings(cluster.Reasons)
+		cluster.SuggestID = bestSong(cluster.Songs).ID
+		clusters = append(clusters, cluster)
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Score != clusters[j].Score {
+			return clusters[i].Score > clusters[j].Score
+		}
+		return clusters[i].Songs[0].ID < clusters[j].Songs[0].ID
+	})
+
+	log.Printf("Found %d duplicate clusters among %d songs", len(clusters), len(candidates))
+	return clusters, nil
+}
+
+// groupBlocks разбивает кандидатов на блоки с одинаковой или нечетко совпадающей группой
+func groupBlocks(candidates []duplicateCandidate, threshold float64) [][]int {
+	var groups []string
+	groupIndex := make(map[string]int)
+	for _, c := range candidates {
+		if _, ok := groupIndex[c.group]; !ok {
+			groupIndex[c.group] = len(groups)
+			groups = append(groups, c.group)
+		}
+	}
+
+	uf := newUnionFind(len(groups))
+	for i := 0; i < len(groups); i++ {
+		for j := i + 1; j < len(groups); j++ {
+			// Быстрое отсечение по длине до вычисления расстояния
+			li, lj := len([]rune(groups[i])), len([]rune(groups[j]))
+			if float64(min(li, lj)) < float64(max(li, lj))*threshold {
+				continue
+			}
+			if stringSimilarity(groups[i], groups[j]) >= threshold {
+				uf.union(i, j)
+			}
+		}
+	}
+
+	blockIndex := make(map[int]int)
+	var blocks [][]int
+	for i, c := range candidates {
+		root := uf.find(groupIndex[c.group])
+		b, ok := blockIndex[root]
+		if !ok {
+			b = len(blocks)
+			blockIndex[root] = b
+			blocks = append(blocks, nil)
+		}
+		blocks[b] = append(blocks[b], i)
+	}
+
+	return blocks
+}
+
+// songCompleteness оценивает полноту данных песни
+func songCompleteness(song models.Song) int {
+	score := 0
+	if song.Text != "" {
+		score += 2
+	}
+	if song.Link != "" {
+		score++
+	}
+	if !song.ReleaseDate.IsZero() {
+		score++
+	}
+	return score
+}
+
+// bestSong выбирает наиболее полную песню, при равенстве — самую раннюю
+func bestSong(songs []models.Song) models.Song {
+	best := songs[0]
+	for _, song := range songs[1:] {
+		if songCompleteness(song) > songCompleteness(best) ||
+			(songCompleteness(song) == songCompleteness(best) && song.ID < best.ID) {
+			best = song
+		}
+	}
+	return best
+}
+
+// pickMergedFields сохраняет поля основной песни, заполняя пустые значениями дубликатов;
+// из текстов выбирается самый полный
+func pickMergedFields(keep models.Song, duplicates []models.Song) models.Song {
+	sorted := append([]models.Song(nil), duplicates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return songCompleteness(sorted[i]) > songCompleteness(sorted[j])
+	})
+
+	for _, d := range sorted {
+		// Более точная дата дубликата заменяет менее точную, если не противоречит ей
+		if keep.ReleaseDate.IsZero() ||
+			(d.ReleaseDate.MorePrecise(keep.ReleaseDate) && keep.ReleaseDate.Contains(d.ReleaseDate)) {
+			keep.ReleaseDate = d.ReleaseDate
+		}
+		if keep.Link == "" {
+			keep.Link = d.Link
+		}
+		if len([]rune(d.Text)) > len([]rune(keep.Text)) {
+			keep.Text = d.Text
+		}
+	}
+
+	return keep
+}
+
+// Merge объединяет дубликаты в выбранную песню
+func (s *DuplicateService) Merge(ctx context.Context, req models.MergeSongsRequest, meta models.AuditMeta) (*models.Song, error) {
+	meta.Source = models.SourceMerge
+
+	if req.KeepID <= 0 {
+		return nil, models.Validation("invalid song ID")
+	}
+	if len(req.DuplicateIDs) == 0 {
+		return nil, models.Validation("duplicate IDs cannot be empty")
+	}
+
+	seen := map[int]bool{req.KeepID: true}
+	var duplicateIDs []int
+	for _, id := range req.DuplicateIDs {
+		if id <= 0 {
+			return nil, models.Validation("invalid song ID %d", id)
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		duplicateIDs = append(duplicateIDs, id)
+	}
+	if len(duplicateIDs) == 0 {
+		return nil, models.Validation("duplicate IDs must differ from keep ID")
+	}
+
+	merged, err := s.repo.MergeSongs(ctx, req.KeepID, duplicateIDs, meta, pickMergedFields)
+	if err != nil {
+		log.Printf("Error merging songs: %v", err)
+		return nil, fmt.Errorf("failed to merge songs: %w", err)
+	}
+
+	log.Printf("Merged songs %v into %d", duplicateIDs, req.KeepID)
+	return merged, nil
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"music-library/internal/config"
+	"music-library/internal/models"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Имена источников обогащения
+const (
+	ProviderAPI         = "api"
+	ProviderFile        = "file"
+	ProviderLyrics      = "lyrics"
+	ProviderMusicBrainz = "musicbrainz"
+)
+
+// enrichableFields поля, которые объединяются из ответов источников
+var enrichableFields = []string{models.FieldReleaseDate, models.FieldText, models.FieldLink}
+
+// EnrichmentProvider источник данных о песне; при отсутствии данных возвращает ErrSongNotFound
+type EnrichmentProvider interface {
+	Name() string
+	Lookup(ctx context.Context, group, song string) (*models.Song, error)
+}
+
+// Name имя внешнего API как источника обогащения
+func (s *ExternalAPIService) Name() string {
+	return ProviderAPI
+}
+
+// Lookup запрашивает данные песни во внешнем API
+func (s *ExternalAPIService) Lookup(ctx context.Context, group, song string) (*models.Song, error) {
+	return s.GetSongDetails(ctx, group, song)
+}
+
+// ProviderChain опрашивает источники по приоритету и объединяет их ответы по полям
+type ProviderChain struct {
+	providers []EnrichmentProvider
+	// fieldOrder переопределяет порядок источников для отдельных полей
+	fieldOrder map[string][]string
+}
+
+// NewProviderChain создает цепочку из источников, уже упорядоченных по приоритету
+func NewProviderChain(providers []EnrichmentProvider, fieldOrder map[string][]string) *ProviderChain {
+	return &ProviderChain{providers: providers, fieldOrder: fieldOrder}
+}
+
+// NewProviderChainFromConfig собирает цепочку источников согласно ENRICHMENT_PROVIDERS
+func NewProviderChainFromConfig(cfg *config.Config, externalAPI *ExternalAPIService) (*ProviderChain, error) {
+	specs, err := parseProviderSpecs(cfg.EnrichmentProviders)
+	if err != nil {
+		return nil, err
+	}
+
+	var providers []EnrichmentProvider
+	for _, spec := range specs {
+		var provider EnrichmentProvider
+		switch spec.name {
+		case ProviderAPI:
+			provider = externalAPI
+		case ProviderFile:
+			provider, err = NewMetadataFileProvider(cfg.EnrichmentMetadataFile)
+		case ProviderLyrics:
+			provider, err = NewLyricsFolderProvider(cfg.EnrichmentLyricsDir)
+		case ProviderMusicBrainz:
+			provider, err = NewMusicBrainzDumpProvider(cfg.EnrichmentMusicBrainzDump)
+		default:
+			return nil, fmt.Errorf("unknown enrichment provider %q", spec.name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to init enrichment provider %s: %w", spec.name, err)
+		}
+		providers = append(providers, provider)
+	}
+
+	fieldOrder, err := parseFieldPriority(cfg.EnrichmentFieldPriority)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(providers))
+	for i, p := range providers {
+		names[i] = p.Name()
+	}
+	for field, order := range fieldOrder {
+		for _, name := range order {
+			if !seenProvider(names, name) {
+				return nil, fmt.Errorf("field priority for %s references unconfigured provider %q", field, name)
+			}
+		}
+	}
+	log.Printf("Enrichment providers: %s", strings.Join(names, ", "))
+
+	return NewProviderChain(providers, fieldOrder), nil
+}
+
+// providerSpec источник и его приоритет; меньшее значение опрашивается раньше
+type providerSpec struct {
+	name     string
+	priority int
+}
+
+// parseProviderSpecs разбирает список вида "api:1,lyrics:2"; без приоритета
+// используется порядок перечисления
+func parseProviderSpecs(value string) ([]providerSpec, error) {
+	var specs []providerSpec
+	seen := make(map[string]bool)
+
+	for i, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, priority, hasPriority := strings.Cut(item, ":")
+		spec := providerSpec{name: strings.TrimSpace(name), priority: i}
+		if hasPriority {
+			p, err := strconv.Atoi(strings.TrimSpace(priority))
+			if err != nil {
+				return nil, fmt.Errorf("invalid priority for enrichment provider %q", spec.name)
+			}
+			spec.priority = p
+		}
+		if seen[spec.name] {
+			return nil, fmt.Errorf("duplicate enrichment provider %q", spec.name)
+		}
+		seen[spec.name] = true
+		specs = append(specs, spec)
+	}
+
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no enrichment providers configured")
+	}
+
+	sort.SliceStable(specs, func(i, j int) bool { return specs[i].priority < specs[j].priority })
+	return specs, nil
+}
+
+// parseFieldPriority разбирает переопределения вида "text:lyrics,api;link:musicbrainz,api"
+func parseFieldPriority(value string) (map[string][]string, error) {
+	order := make(map[string][]string)
+
+	for _, item := range strings.Split(value, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		field, providers, ok := strings.Cut(item, ":")
+		field = strings.TrimSpace(field)
+		if !ok || !isEnrichableField(field) {
+			return nil, fmt.Errorf("invalid field priority %q", item)
+		}
+
+		for _, name := range strings.Split(providers, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				order[field] = append(order[field], name)
+			}
+		}
+	}
+
+	return order, nil
+}
+
+// seenProvider сообщает, присутствует ли источник в списке
+func seenProvider(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isEnrichableField сообщает, заполняется ли поле источниками обогащения
+func isEnrichableField(field string) bool {
+	for _, f := range enrichableFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Name имя цепочки как источника обогащения
+func (c *ProviderChain) Name() string {
+	return "chain"
+}
+
+// Lookup опрашивает источники и объединяет непустые поля; при наличии хотя бы
+// частичных данных ошибки отдельных источников только логируются
+func (c *ProviderChain) Lookup(ctx context.Context, group, song string) (*models.Song, error) {
+	results := make(map[string]*models.Song)
+	queried := make(map[string]bool)
+	var lastErr error
+
+	for _, provider := range c.providers {
+		queried[provider.Name()] = true
+		details, err := provider.Lookup(ctx, group, song)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if !errors.Is(err, ErrSongNotFound) {
+				log.Printf("Enrichment provider %s failed: %v", provider.Name(), err)
+				lastErr = err
+			}
+			continue
+		}
+		results[provider.Name()] = details
+
+		if c.complete(results, queried) {
+			break
+		}
+	}
+
+	if len(results) == 0 {
+		if lastErr != nil {
+			return nil, lastErr
+		}
+		return nil, ErrSongNotFound
+	}
+
+	// Происхождение каждого поля фиксируется для последующей записи
+	now := time.Now()
+	merged := &models.Song{Provenance: make(map[string]models.FieldProvenance)}
+	for _, field := range enrichableFields {
+		for _, name := range c.order(field) {
+			if details, ok := results[name]; ok {
+				if value := details.FieldValue(field); value != "" {
+					merged.SetFieldValue(field, value)
+					merged.Provenance[field] = models.FieldProvenance{Source: name, FetchedAt: now}
+					break
+				}
+			}
+		}
+	}
+
+	return merged, nil
+}
+
+// order возвращает порядок источников для поля: сначала явно заданные, затем остальные по приоритету
+func (c *ProviderChain) order(field string) []string {
+	names := append([]string(nil), c.fieldOrder[field]...)
+	listed := make(map[string]bool)
+	for _, name := range names {
+		listed[name] = true
+	}
+	for _, p := range c.providers {
+		if !listed[p.Name()] {
+			names = append(names, p.Name())
+		}
+	}
+	return names
+}
+
+// complete сообщает, заполнены ли все поля с учетом переопределенного порядка:
+// поле считается заполненным, только если опрошены все источники, стоящие перед найденным
+func (c *ProviderChain) complete(results map[string]*models.Song, queried map[string]bool) bool {
+	for _, field := range enrichableFields {
+		found := false
+		for _, name := range c.order(field) {
+			if !queried[name] {
+				break
+			}
+			if details, ok := results[name]; ok && details.FieldValue(field) != "" {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
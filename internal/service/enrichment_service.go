@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"math/rand"
+	"music-library/internal/config"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+	"sync"
+	"time"
+)
+
+const (
+	// enrichmentLease время, на которое воркер захватывает задачу
+	enrichmentLease = 5 * time.Minute
+	// maxEnrichmentBackoff верхняя граница паузы между попытками
+	maxEnrichmentBackoff = time.Hour
+)
+
+// enrichmentMeta инициатор изменений, вносимых воркерами обогащения
+var enrichmentMeta = models.AuditMeta{Source: models.SourceUnknown, Actor: "system:enrichment"}
+
+// EnrichmentService обрабатывает очередь обогащения песен данными из источников обогащения
+type EnrichmentService struct {
+	repo         *repository.EnrichmentRepository
+	provider     EnrichmentProvider
+	workers      int
+	pollInterval time.Duration
+	maxAttempts  int
+	backoff      time.Duration
+	wg           sync.WaitGroup
+}
+
+// NewEnrichmentService создает новый экземпляр сервиса обогащения
+func NewEnrichmentService(repo *repository.EnrichmentRepository, provider EnrichmentProvider, cfg *config.Config) *EnrichmentService {
+	return &EnrichmentService{
+		repo:         repo,
+		provider:     provider,
+		workers:      max(cfg.EnrichmentWorkers, 1),
+		pollInterval: time.Duration(max(cfg.EnrichmentPollInterval, 1)) * time.Second,
+		maxAttempts:  max(cfg.EnrichmentMaxAttempts, 1),
+		backoff:      time.Duration(max(cfg.EnrichmentBackoff, 1)) * time.Second,
+	}
+}
+
+// Start запускает воркеров, которые работают до отмены ctx
+func (s *EnrichmentService) Start(ctx context.Context) {
+	for i := 0; i < s.workers; i++ {
+		s.wg.Add(1)
+		go func(id int) {
+			defer s.wg.Done()
+			s.runWorker(ctx, id)
+		}(i + 1)
+	}
+
+	log.Printf("Started %d enrichment workers", s.workers)
+}
+
+// Wait ожидает остановки всех воркеров
+func (s *EnrichmentService) Wait() {
+	s.wg.Wait()
+}
+
+// runWorker в цикле забирает и выполняет задачи, засыпая при пустой очереди
+func (s *EnrichmentService) runWorker(ctx context.Context, id int) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		jobs, err := s.repo.ClaimJobs(ctx, 1, enrichmentLease)
+		if err != nil {
+			log.Printf("Enrichment worker %d: error claiming jobs: %v", id, err)
+		}
+		for _, job := range jobs {
+			s.processJob(ctx, job)
+		}
+
+		// Пока в очереди есть задачи, продолжаем без паузы
+		if len(jobs) > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processJob запрашивает данные песни у источников обогащения и сохраняет результат попытки
+func (s *EnrichmentService) processJob(ctx context.Context, job models.EnrichmentJob) {
+	// Результат уже полученного ответа сохраняется и во время остановки воркера
+	saveCtx := context.WithoutCancel(ctx)
+
+	details, err := s.provider.Lookup(ctx, job.Group, job.SongName)
+	if err != nil && ctx.Err() != nil {
+		// Воркер остановлен: задача вернется в очередь без паузы
+		if err := s.repo.RescheduleJob(saveCtx, job, "interrupted by shutdown", time.Now()); err != nil {
+			log.Printf("Error rescheduling enrichment job %d: %v", job.ID, err)
+		}
+		return
+	}
+	if err != nil {
+		s.handleFailure(saveCtx, job, err)
+		return
+	}
+
+	err = s.repo.CompleteJob(saveCtx, job, enrichmentMeta, func(song models.Song) models.Song {
+		return applySongDetails(song, details, s.provider.Name(), job.Overwrite)
+	})
+	if errors.Is(err, repository.ErrJobLeaseLost) {
+		// Задачу уже забрал другой воркер: ее результат сохранит он
+		log.Printf("Enrichment job %d lease expired, dropping result", job.ID)
+		return
+	}
+	if err != nil {
+		log.Printf("Error completing enrichment job %d: %v", job.ID, err)
+		s.handleFailure(saveCtx, job, err)
+		return
+	}
+
+	log.Printf("Enriched song %d (%s by %s)", job.SongID, job.SongName, job.Group)
+	metrics.EnrichmentFinished(metrics.EnrichmentDone)
+}
+
+// handleFailure планирует повторную попытку или окончательно проваливает задачу
+func (s *EnrichmentService) handleFailure(ctx context.Context, job models.EnrichmentJob, cause error) {
+	// Отсутствие песни во внешнем API не исправится повтором
+	if job.Attempts >= s.maxAttempts || errors.Is(cause, ErrSongNotFound) {
+		log.Printf("Enrichment job %d failed after %d attempts: %v", job.ID, job.Attempts, cause)
+		metrics.EnrichmentFinished(metrics.EnrichmentFailed)
+		if err := s.repo.FailJob(ctx, job, cause.Error()); err != nil {
+			log.Printf("Error failing enrichment job %d: %v", job.ID, err)
+		}
+		return
+	}
+
+	delay := retryDelay(s.backoff, job.Attempts)
+	log.Printf("Enrichment job %d attempt %d failed, retrying in %s: %v", job.ID, job.Attempts, delay, cause)
+	metrics.EnrichmentFinished(metrics.EnrichmentRetry)
+	if err := s.repo.RescheduleJob(ctx, job, cause.Error(), time.Now().Add(delay)); err != nil {
+		log.Printf("Error rescheduling enrichment job %d: %v", job.ID, err)
+	}
+}
+
+// retryDelay экспоненциальная пауза перед попыткой attempt+1 со случайным разбросом
+func retryDelay(base time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < maxEnrichmentBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxEnrichmentBackoff {
+		delay = maxEnrichmentBackoff
+	}
+
+	// Разброс в пределах [delay/2, delay) не дает задачам повторяться синхронно
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// applySongDetails заполняет пустые и уточняет неполные поля песни данными обогащения;
+// заполненные поля перезаписываются только при overwrite, заблокированные — никогда
+func applySongDetails(song models.Song, details *models.Song, provider string, overwrite bool) models.Song {
+	// Карта копируется: song передан по значению, но карта общая с вызывающим
+	song.Provenance = maps.Clone(song.Provenance)
+	if song.Provenance == nil {
+		song.Provenance = make(map[string]models.FieldProvenance)
+	}
+
+	for _, field := range enrichableFields {
+		value, err := normalizeSongField(field, details.FieldValue(field))
+		if err != nil {
+			log.Printf("Dropping invalid %s from %s for song %d: %v", field, provider, song.ID, err)
+			continue
+		}
+		if value == "" || song.FieldLocked(field) {
+			continue
+		}
+		if !overwrite && song.FieldValue(field) != "" && !refinesReleaseDate(field, song, value) {
+			continue
+		}
+
+		song.SetFieldValue(field, value)
+
+		prov, ok := details.Provenance[field]
+		if !ok {
+			prov = models.FieldProvenance{Source: provider, FetchedAt: time.Now()}
+		}
+		prov.Locked = false
+		song.Provenance[field] = prov
+	}
+	return song
+}
+
+// refinesReleaseDate сообщает, что источник уточняет неполную дату релиза песни,
+// не противореча ей: "1997" можно заменить на "1997-08-25", но не на "1998-01-01"
+func refinesReleaseDate(field string, song models.Song, value string) bool {
+	if field != models.FieldReleaseDate {
+		return false
+	}
+	date, err := models.ParseReleaseDate(value)
+	return err == nil && date.MorePrecise(song.ReleaseDate) && song.ReleaseDate.Contains(date)
+}
+
+// ListJobs возвращает задачи обогащения с фильтрацией по статусу
+func (s *EnrichmentService) ListJobs(ctx context.Context, status string, page, limit int) ([]models.EnrichmentJob, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20 // значение по умолчанию
+	}
+
+	jobs, err := s.repo.ListJobs(ctx, status, page, limit)
+	if err != nil {
+		log.Printf("Error in ListJobs: %v", err)
+		return nil, fmt.Errorf("failed to retrieve enrichment jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// EnrichSong ставит песню в очередь повторного обогащения; false означает, что задача уже есть
+func (s *EnrichmentService) EnrichSong(ctx context.Context, songID int, overwrite bool) (bool, error) {
+	if songID <= 0 {
+		return false, models.Validation("invalid song ID")
+	}
+
+	queued, err := s.repo.EnqueueSong(ctx, songID, overwrite)
+	if err != nil {
+		log.Printf("Error enqueueing song for enrichment: %v", err)
+		return false, fmt.Errorf("failed to enqueue song: %w", err)
+	}
+
+	log.Printf("Enrichment requested for song %d (queued: %t)", songID, queued)
+	return queued, nil
+}
+
+// EnrichSongs ставит в очередь повторного обогащения песни, подходящие под фильтр
+func (s *EnrichmentService) EnrichSongs(ctx context.Context, req models.EnrichRequest) (int64, error) {
+	if req.Limit < 1 || req.Limit > 10000 {
+		req.Limit = 1000 // значение по умолчанию
+	}
+
+	count, err := s.repo.EnqueueSongs(ctx, req)
+	if err != nil {
+		log.Printf("Error enqueueing songs for enrichment: %v", err)
+		return 0, fmt.Errorf("failed to enqueue songs: %w", err)
+	}
+
+	log.Printf("Enqueued %d songs for enrichment", count)
+	return count, nil
+}
+
+// RetryJob возвращает проваленную задачу в очередь
+func (s *EnrichmentService) RetryJob(ctx context.Context, jobID int64) error {
+	if jobID <= 0 {
+		return models.Validation("invalid job ID")
+	}
+
+	if err := s.repo.RetryJob(ctx, jobID); err != nil {
+		log.Printf("Error retrying enrichment job: %v", err)
+		return fmt.Errorf("failed to retry enrichment job: %w", err)
+	}
+
+	log.Printf("Requeued enrichment job %d", jobID)
+	return nil
+}
+
+// RetryFailed возвращает в очередь все проваленные задачи
+func (s *EnrichmentService) RetryFailed(ctx context.Context) (int64, error) {
+	count, err := s.repo.RetryFailedJobs(ctx)
+	if err != nil {
+		log.Printf("Error retrying failed enrichment jobs: %v", err)
+		return 0, fmt.Errorf("failed to retry enrichment jobs: %w", err)
+	}
+
+	log.Printf("Requeued %d failed enrichment jobs", count)
+	return count, nil
+}
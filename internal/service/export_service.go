@@ -0,0 +1,232 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+	"strconv"
+	"strings"
+)
+
+// Поддерживаемые форматы выгрузки
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatJSON   = "json"
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatM3U    = "m3u"
+	ExportFormatXSPF   = "xspf"
+)
+
+// exportFormats описывает MIME-тип и расширение файла каждого формата
+var exportFormats = map[string]struct {
+	contentType string
+	extension   string
+}{
+	ExportFormatCSV:    {"text/csv; charset=utf-8", "csv"},
+	ExportFormatJSON:   {"application/json", "json"},
+	ExportFormatNDJSON: {"application/x-ndjson", "ndjson"},
+	ExportFormatM3U:    {"audio/x-mpegurl", "m3u"},
+	ExportFormatXSPF:   {"application/xspf+xml", "xspf"},
+}
+
+// ExportService выгружает библиотеку в различных форматах
+type ExportService struct {
+	repo *repository.SongRepository
+}
+
+// NewExportService создает новый экземпляр сервиса выгрузки
+func NewExportService(repo *repository.SongRepository) *ExportService {
+	return &ExportService{repo: repo}
+}
+
+// ExportContentType возвращает MIME-тип и расширение файла для формата
+func ExportContentType(format string) (contentType, extension string, ok bool) {
+	f, ok := exportFormats[format]
+	return f.contentType, f.extension, ok
+}
+
+// Export построчно записывает в w песни, подходящие под фильтр, в заданном формате
+func (s *ExportService) Export(ctx context.Context, w io.Writer, format string, filter models.SongFilter) error {
+	buf := bufio.NewWriter(w)
+
+	writer, err := newSongWriter(buf, format)
+	if err != nil {
+		return err
+	}
+
+	if err := writer.begin(); err != nil {
+		return err
+	}
+
+	count := 0
+	err = s.repo.StreamSongs(ctx, filter, func(song models.Song) error {
+		count++
+		return writer.write(song)
+	})
+	if err != nil {
+		log.Printf("Error exporting songs: %v", err)
+		return fmt.Errorf("failed to export songs: %w", err)
+	}
+
+	if err := writer.end(); err != nil {
+		return err
+	}
+
+	log.Printf("Exported %d songs as %s", count, format)
+	return buf.Flush()
+}
+
+// songWriter последовательно сериализует песни в выбранном формате
+type songWriter interface {
+	begin() error
+	write(song models.Song) error
+	end() error
+}
+
+// newSongWriter создает сериализатор для формата выгрузки
+func newSongWriter(w *bufio.Writer, format string) (songWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		return &csvSongWriter{w: csv.NewWriter(w)}, nil
+	case ExportFormatJSON:
+		return &jsonSongWriter{w: w}, nil
+	case ExportFormatNDJSON:
+		return &ndjsonSongWriter{enc: json.NewEncoder(w)}, nil
+	case ExportFormatM3U:
+		return &m3uSongWriter{w: w}, nil
+	case ExportFormatXSPF:
+		return &xspfSongWriter{w: w, enc: xml.NewEncoder(w)}, nil
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+// csvSongWriter выгрузка в CSV с заголовком, совместимым с импортом
+type csvSongWriter struct {
+	w *csv.Writer
+}
+
+func (cw *csvSongWriter) begin() error {
+	return cw.w.Write([]string{"id", "group", "song", "release_date", "text", "link"})
+}
+
+func (cw *csvSongWriter) write(song models.Song) error {
+	return cw.w.Write([]string{
+		strconv.Itoa(song.ID), song.Group, song.SongName,
+		song.ReleaseDate.String(), song.Text, song.Link,
+	})
+}
+
+func (cw *csvSongWriter) end() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// jsonSongWriter выгрузка в виде JSON-массива
+type jsonSongWriter struct {
+	w     *bufio.Writer
+	count int
+}
+
+func (jw *jsonSongWriter) begin() error {
+	_, err := jw.w.WriteString("[")
+	return err
+}
+
+func (jw *jsonSongWriter) write(song models.Song) error {
+	data, err := json.Marshal(song)
+	if err != nil {
+		return err
+	}
+	if jw.count > 0 {
+		if err := jw.w.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	jw.count++
+	_, err = jw.w.Write(data)
+	return err
+}
+
+func (jw *jsonSongWriter) end() error {
+	_, err := jw.w.WriteString("]\n")
+	return err
+}
+
+// ndjsonSongWriter выгрузка по одному JSON-объекту на строку
+type ndjsonSongWriter struct {
+	enc *json.Encoder
+}
+
+func (nw *ndjsonSongWriter) begin() error { return nil }
+
+func (nw *ndjsonSongWriter) write(song models.Song) error { return nw.enc.Encode(song) }
+
+func (nw *ndjsonSongWriter) end() error { return nil }
+
+// m3uSongWriter плейлист M3U; песни без ссылки пропускаются
+type m3uSongWriter struct {
+	w *bufio.Writer
+}
+
+func (mw *m3uSongWriter) begin() error {
+	_, err := mw.w.WriteString("#EXTM3U\n")
+	return err
+}
+
+func (mw *m3uSongWriter) write(song models.Song) error {
+	if song.Link == "" {
+		return nil
+	}
+	// Переводы строк внутри названий ломают формат плейлиста
+	title := strings.NewReplacer("\r", " ", "\n", " ").Replace(song.Group + " - " + song.SongName)
+	_, err := fmt.Fprintf(mw.w, "#EXTINF:-1,%s\n%s\n", title, song.Link)
+	return err
+}
+
+func (mw *m3uSongWriter) end() error { return nil }
+
+// xspfTrack элемент trackList плейлиста XSPF
+type xspfTrack struct {
+	XMLName  xml.Name `xml:"track"`
+	Location string   `xml:"location"`
+	Creator  string   `xml:"creator"`
+	Title    string   `xml:"title"`
+}
+
+// xspfSongWriter плейлист XSPF; песни без ссылки пропускаются
+type xspfSongWriter struct {
+	w   *bufio.Writer
+	enc *xml.Encoder
+}
+
+func (xw *xspfSongWriter) begin() error {
+	_, err := xw.w.WriteString(xml.Header +
+		`<playlist version="1" xmlns="http://xspf.org/ns/0/"><trackList>`)
+	return err
+}
+
+func (xw *xspfSongWriter) write(song models.Song) error {
+	if song.Link == "" {
+		return nil
+	}
+	return xw.enc.Encode(xspfTrack{
+		Location: song.Link,
+		Creator:  song.Group,
+		Title:    song.SongName,
+	})
+}
+
+func (xw *xspfSongWriter) end() error {
+	if err := xw.enc.Flush(); err != nil {
+		return err
+	}
+	_, err := xw.w.WriteString("</trackList></playlist>\n")
+	return err
+}
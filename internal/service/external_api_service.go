@@ -1,60 +1,383 @@
 package service
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math/rand"
 	"music-library/internal/config"
+	"music-library/internal/metrics"
 	"music-library/internal/models"
+	"net"
 	"net/http"
+	"net/url"
 	"time"
 )
 
-type ExternalAPIService struct {
+// Ошибки внешнего API
+var (
+	// ErrSongNotFound внешний API не знает такой песни; повтор запроса не поможет
+	ErrSongNotFound error = models.NotFound("song not found in external API")
+	// ErrUpstreamUnavailable внешний API недоступен или отвечает ошибкой сервера
+	ErrUpstreamUnavailable error = models.UpstreamUnavailable("external API unavailable")
+	// ErrCircuitOpen запрос не отправлен, так как выключатель разомкнут
+	ErrCircuitOpen = fmt.Errorf("%w: circuit breaker is open", ErrUpstreamUnavailable)
+	// ErrInvalidResponse внешний API вернул ответ, который не удалось разобрать
+	ErrInvalidResponse error = models.UpstreamInvalid("invalid response from external API")
+)
+
+// apiEndpoint один из адресов внешнего API со своим выключателем и ограничением частоты
+type apiEndpoint struct {
 	baseURL string
-	timeout time.Duration
+	breaker *CircuitBreaker
+	limiter *RateLimiter
+}
+
+type ExternalAPIService struct {
+	endpoints  []*apiEndpoint
+	client     *http.Client
+	auth       *requestAuthenticator
+	maxRetries int
+	retryBase  time.Duration
+	retryMax   time.Duration
+	mapping    *ResponseMapping
+
+	cache       DetailsCache
+	cacheTTL    time.Duration
+	negativeTTL time.Duration
 }
 
-func NewExternalAPIService(cfg *config.Config) *ExternalAPIService {
+func NewExternalAPIService(cfg *config.Config, cache DetailsCache) (*ExternalAPIService, error) {
+	// Общий транспорт переиспользует соединения между запросами
+	var transport http.RoundTripper = &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         (&net.Dialer{Timeout: 5 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 20,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 5 * time.Second,
+	}
+
+	if cfg.APICassetteMode != "" && cfg.APICassetteMode != CassetteOff {
+		cassettes, err := NewCassetteTransport(cfg.APICassetteMode, cfg.APICassetteDir, transport)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("External API cassettes: mode %s, directory %s", cfg.APICassetteMode, cfg.APICassetteDir)
+		transport = cassettes
+	}
+
+	auth, err := newRequestAuthenticator(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Адреса перечисляются в порядке предпочтения: основной, затем зеркала
+	var endpoints []*apiEndpoint
+	for _, baseURL := range splitList(cfg.APIBaseURL) {
+		if _, err := url.Parse(baseURL); err != nil {
+			return nil, fmt.Errorf("invalid API base URL %q: %w", baseURL, err)
+		}
+		endpoints = append(endpoints, &apiEndpoint{
+			baseURL: baseURL,
+			breaker: NewCircuitBreaker(cfg.APIBreakerThreshold, time.Duration(cfg.APIBreakerCooldown)*time.Second),
+			limiter: NewRateLimiter(cfg.APIRateLimit, cfg.APIRateBurst),
+		})
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("API_BASE_URL is not set")
+	}
+
 	return &ExternalAPIService{
-		baseURL: cfg.APIBaseURL,
-		timeout: time.Duration(cfg.APITimeout) * time.Second,
+		endpoints: endpoints,
+		auth:      auth,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   time.Duration(cfg.APITimeout) * time.Second,
+		},
+		maxRetries: max(cfg.APIMaxRetries, 0),
+		retryBase:  time.Duration(cfg.APIRetryBaseDelay) * time.Millisecond,
+		retryMax:   time.Duration(cfg.APIRetryMaxDelay) * time.Millisecond,
+		mapping:    NewResponseMappingFromConfig(cfg),
+
+		cache:       cache,
+		cacheTTL:    time.Duration(cfg.CacheTTL) * time.Second,
+		negativeTTL: time.Duration(cfg.CacheNegativeTTL) * time.Second,
+	}, nil
+}
+
+// EndpointStates возвращает состояние выключателя каждого адреса внешнего API
+func (s *ExternalAPIService) EndpointStates() map[string]string {
+	states := make(map[string]string, len(s.endpoints))
+	for _, endpoint := range s.endpoints {
+		states[endpoint.baseURL] = endpoint.breaker.State()
 	}
+	return states
 }
 
-func (s *ExternalAPIService) GetSongDetails(group, song string) (*models.Song, error) {
-	client := &http.Client{Timeout: s.timeout}
+// CheckEndpoints проверяет доступность каждого адреса внешнего API установкой
+// TCP-соединения и сообщает состояние его выключателя
+func (s *ExternalAPIService) CheckEndpoints(ctx context.Context) []models.UpstreamStatus {
+	statuses := make([]models.UpstreamStatus, 0, len(s.endpoints))
+	for _, endpoint := range s.endpoints {
+		status := models.UpstreamStatus{URL: endpoint.baseURL, Circuit: endpoint.breaker.State()}
+		if err := dialEndpoint(ctx, endpoint.baseURL); err != nil {
+			status.Error = err.Error()
+		} else {
+			status.Reachable = true
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
 
-	url := fmt.Sprintf("%s/info?group=%s&song=%s", s.baseURL, group, song)
-	req, err := http.NewRequest("GET", url, nil)
+// dialEndpoint устанавливает и сразу закрывает TCP-соединение с хостом адреса
+func dialEndpoint(ctx context.Context, baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return err
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// GetSongDetails возвращает данные песни из кэша или запрашивает их во внешнем API;
+// ответ 404 кэшируется отдельно с более коротким сроком жизни
+func (s *ExternalAPIService) GetSongDetails(ctx context.Context, group, song string) (*models.Song, error) {
+	if s.cache == nil {
+		return s.requestSongDetails(ctx, group, song)
+	}
+
+	key := detailsCacheKey(group, song)
+	entry, err := s.cache.Get(ctx, key)
+	if err != nil {
+		// Недоступный кэш не должен мешать обращению к API
+		log.Printf("Error reading details cache: %v", err)
+	}
+	if entry != nil && entry.NotFound {
+		return nil, ErrSongNotFound
+	}
+	if entry != nil && entry.Details != nil {
+		details := *entry.Details
+		return &details, nil
+	}
+
+	details, err := s.requestSongDetails(ctx, group, song)
+	switch {
+	case err == nil:
+		s.storeCacheEntry(ctx, key, group, song, details, s.cacheTTL)
+	case errors.Is(err, ErrSongNotFound):
+		s.storeCacheEntry(ctx, key, group, song, nil, s.negativeTTL)
+	}
+
+	return details, err
+}
+
+// storeCacheEntry сохраняет ответ в кэш; details == nil означает отрицательный ответ
+func (s *ExternalAPIService) storeCacheEntry(ctx context.Context, key, group, song string, details *models.Song, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	now := time.Now()
+	entry := &models.DetailsCacheEntry{
+		Key:       key,
+		Group:     group,
+		Song:      song,
+		NotFound:  details == nil,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	if details != nil {
+		cached := *details
+		entry.Details = &cached
+	}
+
+	if err := s.cache.Set(ctx, entry); err != nil {
+		log.Printf("Error writing details cache: %v", err)
+	}
+}
+
+// requestSongDetails обходит адреса внешнего API по порядку: адрес с разомкнутым
+// выключателем пропускается, а после исчерпания повторов на одном адресе запрос
+// переходит к следующему
+func (s *ExternalAPIService) requestSongDetails(ctx context.Context, group, song string) (*models.Song, error) {
+	lastErr := ErrCircuitOpen
+	for _, endpoint := range s.endpoints {
+		if !endpoint.breaker.Allow() {
+			continue
+		}
+
+		details, err := s.requestFromEndpoint(ctx, endpoint, group, song)
+		switch {
+		case err == nil:
+			endpoint.breaker.Success()
+			return details, nil
+		case ctx.Err() != nil:
+			// Отмена вызывающей стороной не говорит о состоянии внешнего API
+			endpoint.breaker.Release()
+			return nil, ctx.Err()
+		case !errors.Is(err, ErrUpstreamUnavailable):
+			// Сервис ответил осмысленно, значит он работоспособен
+			endpoint.breaker.Success()
+			return nil, err
+		}
+
+		endpoint.breaker.Failure()
+		log.Printf("External API endpoint %s failed, trying next one: %v", endpoint.baseURL, err)
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
+
+// requestFromEndpoint запрашивает данные песни по одному адресу, повторяя запрос
+// при сбоях сервера и таймаутах
+func (s *ExternalAPIService) requestFromEndpoint(ctx context.Context, endpoint *apiEndpoint, group, song string) (*models.Song, error) {
+	var lastErr error
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			delay := s.backoff(attempt)
+			log.Printf("Retrying external API request in %s (attempt %d): %v", delay, attempt+1, lastErr)
+
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		if err := endpoint.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		details, err := s.fetchSongDetails(ctx, endpoint.baseURL, group, song)
+		metrics.ObserveUpstream(endpoint.baseURL, upstreamOutcome(err), time.Since(start))
+		if err == nil {
+			return details, nil
+		}
+		lastErr = err
+
+		if ctx.Err() != nil || !errors.Is(err, ErrUpstreamUnavailable) {
+			return nil, err
+		}
+	}
+
+	return nil, lastErr
+}
+
+// upstreamOutcome исход вызова внешнего API для метрик
+func upstreamOutcome(err error) string {
+	switch {
+	case err == nil:
+		return metrics.OutcomeOK
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return metrics.OutcomeCanceled
+	case errors.Is(err, ErrSongNotFound):
+		return metrics.OutcomeNotFound
+	case errors.Is(err, ErrUpstreamUnavailable):
+		return metrics.OutcomeUnavailable
+	case errors.Is(err, ErrInvalidResponse):
+		return metrics.OutcomeInvalid
+	default:
+		return metrics.OutcomeError
+	}
+}
+
+// backoff экспоненциальная пауза перед повтором с полным случайным разбросом
+func (s *ExternalAPIService) backoff(attempt int) time.Duration {
+	delay := s.retryBase << (attempt - 1)
+	if delay <= 0 || delay > s.retryMax {
+		delay = s.retryMax
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// songDetailsURL строит адрес запроса с корректным кодированием параметров
+func songDetailsURL(baseURL, group, song string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid API base URL: %w", err)
+	}
+
+	u = u.JoinPath("info")
+	u.RawQuery = url.Values{"group": {group}, "song": {song}}.Encode()
+
+	return u.String(), nil
+}
+
+// fetchSongDetails выполняет одну попытку запроса и классифицирует ошибку
+func (s *ExternalAPIService) fetchSongDetails(ctx context.Context, baseURL, group, song string) (*models.Song, error) {
+	reqURL, err := songDetailsURL(baseURL, group, song)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
 	if err != nil {
 		log.Printf("Error creating request: %v", err)
 		return nil, err
 	}
+	req.Header.Set("Accept", "application/json")
+	s.auth.apply(req)
 
-	resp, err := client.Do(req)
+	resp, err := s.client.Do(req)
+	if errors.Is(err, ErrCassetteMismatch) {
+		// Повтор не поможет: запись появится только после нового сеанса record
+		return nil, err
+	}
 	if err != nil {
 		log.Printf("Error making request: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", ErrUpstreamUnavailable, err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("external API returned status %d", resp.StatusCode)
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrSongNotFound
+	case retryableStatus(resp.StatusCode):
+		return nil, fmt.Errorf("%w: status %d", ErrUpstreamUnavailable, resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%w: status %d", ErrInvalidResponse, resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		log.Printf("Error reading response body: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", ErrUpstreamUnavailable, err)
 	}
 
-	var songDetails models.Song
-	if err := json.Unmarshal(body, &songDetails); err != nil {
+	songDetails, err := s.mapping.Map(body)
+	if err != nil {
 		log.Printf("Error parsing response: %v", err)
 		return nil, err
 	}
+	songDetails.Group = group
+	songDetails.SongName = song
+
+	return songDetails, nil
+}
 
-	return &songDetails, nil
+// retryableStatus сообщает, что ответ с этим кодом временный и запрос стоит повторить
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
 }
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"music-library/internal/config"
+)
+
+// upstreamStub тестовый адрес внешнего API, отвечающий фиксированным статусом
+type upstreamStub struct {
+	server *httptest.Server
+	calls  atomic.Int32
+}
+
+func newUpstreamStub(t *testing.T, status int, body string) *upstreamStub {
+	t.Helper()
+
+	stub := &upstreamStub{}
+	stub.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(stub.server.Close)
+	return stub
+}
+
+// newTestExternalAPI создает клиент без кэша и повторов, чтобы каждый адрес
+// получал ровно одну попытку, а выключатель размыкался после первого сбоя
+func newTestExternalAPI(t *testing.T, baseURLs string) *ExternalAPIService {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.APIBaseURL = baseURLs
+	cfg.APIMaxRetries = 0
+	cfg.APIBreakerThreshold = 1
+	cfg.APIBreakerCooldown = 3600
+
+	api, err := NewExternalAPIService(cfg, nil)
+	if err != nil {
+		t.Fatalf("NewExternalAPIService: %v", err)
+	}
+	return api
+}
+
+const mirrorDetails = `{"releaseDate": "16.07.2006", "text": "Ooh baby", "link": "https://example.com/song"}`
+
+func TestRequestSongDetailsFailsOverToMirror(t *testing.T) {
+	primary := newUpstreamStub(t, http.StatusServiceUnavailable, `{}`)
+	mirror := newUpstreamStub(t, http.StatusOK, mirrorDetails)
+	api := newTestExternalAPI(t, primary.server.URL+","+mirror.server.URL)
+
+	details, err := api.GetSongDetails(context.Background(), "Muse", "Supermassive Black Hole")
+	if err != nil {
+		t.Fatalf("GetSongDetails: %v", err)
+	}
+	if details.Text != "Ooh baby" || details.ReleaseDate.String() != "2006-07-16" {
+		t.Fatalf("details = %+v, want mirror response", details)
+	}
+	if primary.calls.Load() != 1 || mirror.calls.Load() != 1 {
+		t.Fatalf("calls: primary %d, mirror %d, want 1 and 1", primary.calls.Load(), mirror.calls.Load())
+	}
+
+	states := api.EndpointStates()
+	if got := states[primary.server.URL]; got != BreakerOpen {
+		t.Errorf("primary breaker = %s, want %s", got, BreakerOpen)
+	}
+	if got := states[mirror.server.URL]; got != BreakerClosed {
+		t.Errorf("mirror breaker = %s, want %s", got, BreakerClosed)
+	}
+}
+
+func TestRequestSongDetailsSkipsOpenBreaker(t *testing.T) {
+	primary := newUpstreamStub(t, http.StatusOK, mirrorDetails)
+	mirror := newUpstreamStub(t, http.StatusOK, mirrorDetails)
+	api := newTestExternalAPI(t, primary.server.URL+","+mirror.server.URL)
+
+	api.endpoints[0].breaker.Failure()
+
+	if _, err := api.GetSongDetails(context.Background(), "Muse", "Supermassive Black Hole"); err != nil {
+		t.Fatalf("GetSongDetails: %v", err)
+	}
+	if primary.calls.Load() != 0 {
+		t.Errorf("primary with open breaker received %d calls, want 0", primary.calls.Load())
+	}
+	if mirror.calls.Load() != 1 {
+		t.Errorf("mirror received %d calls, want 1", mirror.calls.Load())
+	}
+}
+
+func TestRequestSongDetailsAllBreakersOpen(t *testing.T) {
+	primary := newUpstreamStub(t, http.StatusOK, mirrorDetails)
+	api := newTestExternalAPI(t, primary.server.URL)
+
+	api.endpoints[0].breaker.Failure()
+
+	_, err := api.GetSongDetails(context.Background(), "Muse", "Supermassive Black Hole")
+	if err != ErrCircuitOpen {
+		t.Fatalf("err = %v, want %v", err, ErrCircuitOpen)
+	}
+	if primary.calls.Load() != 0 {
+		t.Errorf("primary received %d calls, want 0", primary.calls.Load())
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"music-library/internal/models"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// MetadataFileProvider отдает данные из локального файла метаданных в формате импорта
+// (CSV, JSON или NDJSON с полями group, song, release_date, text, link)
+type MetadataFileProvider struct {
+	songs map[string]models.Song
+}
+
+// NewMetadataFileProvider загружает файл метаданных в память
+func NewMetadataFileProvider(path string) (*MetadataFileProvider, error) {
+	if path == "" {
+		return nil, fmt.Errorf("ENRICHMENT_METADATA_FILE is not set")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	next, err := newRowReader(f, DetectImportFormat(path))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := &MetadataFileProvider{songs: make(map[string]models.Song)}
+	for row := 1; ; row++ {
+		item, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+
+		song, err := validateImportRow(item)
+		if err != nil {
+			log.Printf("Skipping metadata file row %d: %v", row, err)
+			continue
+		}
+		provider.songs[detailsCacheKey(song.Group, song.SongName)] = *song
+	}
+
+	log.Printf("Loaded %d songs from metadata file %s", len(provider.songs), path)
+	return provider, nil
+}
+
+func (p *MetadataFileProvider) Name() string {
+	return ProviderFile
+}
+
+func (p *MetadataFileProvider) Lookup(ctx context.Context, group, song string) (*models.Song, error) {
+	details, ok := p.songs[detailsCacheKey(group, song)]
+	if !ok {
+		return nil, ErrSongNotFound
+	}
+	return &details, nil
+}
+
+// Временные метки ([00:12.34]) и строки метаданных ([ar:Artist]) формата LRC
+var (
+	lrcTimestamp = regexp.MustCompile(`\[\d{1,3}:\d{2}(?:[.:]\d{1,3})?\]`)
+	lrcMetadata  = regexp.MustCompile(`^\[[A-Za-z#]+:[^\]]*\]$`)
+)
+
+// LyricsFolderProvider отдает тексты из папки с файлами .lrc и .txt, названными
+// "Группа - Песня.lrc" или разложенными как "Группа/Песня.lrc"
+type LyricsFolderProvider struct {
+	files map[string]string
+}
+
+// NewLyricsFolderProvider индексирует файлы текстов в папке
+func NewLyricsFolderProvider(dir string) (*LyricsFolderProvider, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("ENRICHMENT_LYRICS_DIR is not set")
+	}
+
+	provider := &LyricsFolderProvider{files: make(map[string]string)}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext != ".lrc" && ext != ".txt" {
+			return nil
+		}
+
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		group, song, ok := strings.Cut(name, " - ")
+		if !ok {
+			// Файл лежит в папке группы
+			rel, _ := filepath.Rel(dir, filepath.Dir(path))
+			if rel == "." {
+				return nil
+			}
+			group, song = filepath.Base(rel), name
+		}
+
+		key := detailsCacheKey(group, song)
+		// .txt с простым текстом предпочтительнее .lrc с тем же названием
+		if existing, ok := provider.files[key]; ok && strings.EqualFold(filepath.Ext(existing), ".txt") {
+			return nil
+		}
+		provider.files[key] = path
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Indexed %d lyrics files in %s", len(provider.files), dir)
+	return provider, nil
+}
+
+func (p *LyricsFolderProvider) Name() string {
+	return ProviderLyrics
+}
+
+func (p *LyricsFolderProvider) Lookup(ctx context.Context, group, song string) (*models.Song, error) {
+	path, ok := p.files[detailsCacheKey(group, song)]
+	if !ok {
+		return nil, ErrSongNotFound
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	text := string(data)
+	if strings.EqualFold(filepath.Ext(path), ".lrc") {
+		text = stripLRC(text)
+	}
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return nil, ErrSongNotFound
+	}
+
+	return &models.Song{Text: text}, nil
+}
+
+// stripLRC убирает из LRC временные метки и строки метаданных, сохраняя пустые строки между куплетами
+func stripLRC(text string) string {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		raw := strings.TrimSpace(scanner.Text())
+		if lrcMetadata.MatchString(raw) {
+			continue
+		}
+		lines = append(lines, strings.TrimSpace(lrcTimestamp.ReplaceAllString(raw, "")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// musicBrainzRecording запись дампа в формате JSON API MusicBrainz
+type musicBrainzRecording struct {
+	Title            string `json:"title"`
+	FirstReleaseDate string `json:"first-release-date"`
+	ArtistCredit     []struct {
+		Name       string `json:"name"`
+		JoinPhrase string `json:"joinphrase"`
+	} `json:"artist-credit"`
+	Relations []struct {
+		Type string `json:"type"`
+		URL  struct {
+			Resource string `json:"resource"`
+		} `json:"url"`
+	} `json:"relations"`
+}
+
+// MusicBrainzDumpProvider отдает даты и ссылки из дампа записей MusicBrainz (JSON Lines)
+type MusicBrainzDumpProvider struct {
+	songs map[string]models.Song
+}
+
+// NewMusicBrainzDumpProvider загружает дамп записей в память
+func NewMusicBrainzDumpProvider(path string) (*MusicBrainzDumpProvider, error) {
+	if path == "" {
+		return nil, fmt.Errorf("ENRICHMENT_MUSICBRAINZ_DUMP is not set")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	provider := &MusicBrainzDumpProvider{songs: make(map[string]models.Song)}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		data := strings.TrimSpace(scanner.Text())
+		if data == "" {
+			continue
+		}
+
+		var rec musicBrainzRecording
+		if err := json.Unmarshal([]byte(data), &rec); err != nil {
+			log.Printf("Skipping MusicBrainz dump line %d: %v", line, err)
+			continue
+		}
+
+		var artist strings.Builder
+		for _, credit := range rec.ArtistCredit {
+			artist.WriteString(credit.Name + credit.JoinPhrase)
+		}
+		if artist.Len() == 0 || rec.Title == "" {
+			continue
+		}
+
+		song := models.Song{Group: artist.String(), SongName: rec.Title}
+		if date, err := models.ParseReleaseDate(rec.FirstReleaseDate); err == nil {
+			song.ReleaseDate = date
+		}
+		for _, rel := range rec.Relations {
+			if rel.URL.Resource != "" && (rel.Type == "free streaming" || rel.Type == "streaming" || song.Link == "") {
+				song.Link = rel.URL.Resource
+			}
+		}
+
+		// Первая запись считается основной: дубликаты из других релизов не перетирают ее
+		key := detailsCacheKey(song.Group, song.SongName)
+		if _, exists := provider.songs[key]; !exists {
+			provider.songs[key] = song
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Loaded %d recordings from MusicBrainz dump %s", len(provider.songs), path)
+	return provider, nil
+}
+
+func (p *MusicBrainzDumpProvider) Name() string {
+	return ProviderMusicBrainz
+}
+
+func (p *MusicBrainzDumpProvider) Lookup(ctx context.Context, group, song string) (*models.Song, error) {
+	details, ok := p.songs[detailsCacheKey(group, song)]
+	if !ok {
+		return nil, ErrSongNotFound
+	}
+	return &details, nil
+}
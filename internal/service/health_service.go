@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"time"
+)
+
+// healthCheckTimeout срок каждой проверки готовности, чтобы зависшая зависимость
+// не задерживала ответ пробе оркестратора
+const healthCheckTimeout = 2 * time.Second
+
+// Pinger проверяет соединение с базой данных; ему соответствует *sql.DB
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// HealthService проверяет готовность приложения и его зависимостей
+type HealthService struct {
+	db          Pinger
+	migrations  *repository.MigrationRepository
+	externalAPI *ExternalAPIService
+	build       models.BuildInfo
+}
+
+// NewHealthService создает сервис проверок; version — версия сборки для отчета
+func NewHealthService(db Pinger, migrations *repository.MigrationRepository, externalAPI *ExternalAPIService, version string) *HealthService {
+	return &HealthService{
+		db:          db,
+		migrations:  migrations,
+		externalAPI: externalAPI,
+		build:       readBuildInfo(version),
+	}
+}
+
+// Readiness проверяет базу данных, миграции и внешнее API. Приложение готово,
+// если база доступна и все миграции применены
+func (s *HealthService) Readiness(ctx context.Context) models.Readiness {
+	report := models.Readiness{
+		Status:   models.HealthReady,
+		Database: s.checkDatabase(ctx),
+		Build:    s.build,
+	}
+
+	if report.Database.Status == models.HealthOK {
+		report.Migrations = s.checkMigrations(ctx)
+	} else {
+		report.Migrations = models.MigrationStatus{Status: models.HealthDown, Pending: []string{}, Error: "database is unavailable"}
+	}
+
+	report.ExternalAPI = []models.UpstreamStatus{}
+	if s.externalAPI != nil {
+		checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		defer cancel()
+		report.ExternalAPI = s.externalAPI.CheckEndpoints(checkCtx)
+	}
+
+	if report.Database.Status != models.HealthOK || report.Migrations.Status != models.HealthOK {
+		report.Status = models.HealthNotReady
+	}
+	return report
+}
+
+// checkDatabase выполняет ping базы данных с ограниченным сроком
+func (s *HealthService) checkDatabase(ctx context.Context) models.DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := s.db.PingContext(ctx)
+	status := models.DependencyStatus{Status: models.HealthOK, LatencyMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		status.Status = models.HealthDown
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// checkMigrations сравнивает примененные миграции с известными приложению
+func (s *HealthService) checkMigrations(ctx context.Context) models.MigrationStatus {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	versions, err := s.migrations.Versions()
+	if err != nil {
+		return models.MigrationStatus{Status: models.HealthDown, Pending: []string{}, Error: err.Error()}
+	}
+	applied, err := s.migrations.Applied(ctx)
+	if err != nil {
+		return models.MigrationStatus{Status: models.HealthDown, Pending: []string{}, Error: err.Error()}
+	}
+
+	status := models.MigrationStatus{Status: models.HealthOK, Applied: len(applied), Pending: []string{}}
+	if len(applied) > 0 {
+		status.Current = applied[len(applied)-1]
+	}
+	for _, version := range versions {
+		if !slices.Contains(applied, version) {
+			status.Pending = append(status.Pending, version)
+		}
+	}
+	if len(status.Pending) > 0 {
+		status.Status = models.HealthNotReady
+	}
+	return status
+}
+
+// readBuildInfo собирает сведения о сборке из метаданных, которые записывает компилятор
+func readBuildInfo(version string) models.BuildInfo {
+	build := models.BuildInfo{Version: version, GoVersion: runtime.Version()}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return build
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			build.Revision = setting.Value
+		case "vcs.time":
+			build.BuildTime = setting.Value
+		case "vcs.modified":
+			build.Modified = setting.Value == "true"
+		}
+	}
+	return build
+}
@@ -0,0 +1,382 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+	"path/filepath"
+	"strings"
+)
+
+// Поддерживаемые форматы файлов импорта
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatJSON   = "json"
+	ImportFormatNDJSON = "ndjson"
+)
+
+const (
+	defaultImportBatchSize = 500
+	maxImportBatchSize     = 5000
+	maxImportErrors        = 1000
+)
+
+// ImportService выполняет пакетный импорт песен из файлов
+type ImportService struct {
+	repo *repository.SongRepository
+}
+
+// NewImportService создает новый экземпляр сервиса импорта
+func NewImportService(repo *repository.SongRepository) *ImportService {
+	return &ImportService{repo: repo}
+}
+
+// DetectImportFormat определяет формат файла по его имени
+func DetectImportFormat(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return ImportFormatCSV
+	case ".json":
+		return ImportFormatJSON
+	case ".ndjson", ".jsonl":
+		return ImportFormatNDJSON
+	}
+	return ""
+}
+
+// Import читает песни из r, проверяет и дедуплицирует строки и сохраняет их пачками
+func (s *ImportService) Import(ctx context.Context, r io.Reader, opts models.ImportOptions, meta models.AuditMeta) (*models.ImportReport, error) {
+	meta.Source = models.SourceImport
+
+	if opts.BatchSize < 1 || opts.BatchSize > maxImportBatchSize {
+		opts.BatchSize = defaultImportBatchSize
+	}
+
+	next, err := newRowReader(r, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ImportReport{Errors: []models.ImportRowError{}}
+	seen := make(map[[2]string]bool)
+	var batch []*models.Song
+
+	// Сохранение накопленной пачки с проверкой на уже существующие песни
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := s.saveBatch(ctx, batch, opts, meta, report)
+		batch = batch[:0]
+		return err
+	}
+
+	for row := 1; ; row++ {
+		if err := ctx.Err(); err != nil {
+			return report, fmt.Errorf("import interrupted: %w", err)
+		}
+
+		item, err := next()
+		if err == io.EOF {
+			break
+		}
+		report.Total++
+		if err != nil {
+			var rowErr *rowParseError
+			if errors.As(err, &rowErr) {
+				addImportError(report, row, err)
+				continue
+			}
+			if errors.Is(err, models.ErrValidation) {
+				return report, err
+			}
+			return report, fmt.Errorf("failed to read import file: %w", err)
+		}
+
+		song, err := validateImportRow(item)
+		if err != nil {
+			addImportError(report, row, err)
+			continue
+		}
+
+		// Недостающие поля дополнит очередь обогащения
+		if opts.Enrich && (song.Text == "" || song.Link == "") {
+			song.EnrichmentStatus = models.EnrichmentPending
+		}
+
+		key := songKey(song.Group, song.SongName)
+		if seen[key] {
+			report.Duplicates++
+			continue
+		}
+		seen[key] = true
+
+		batch = append(batch, song)
+		if len(batch) >= opts.BatchSize {
+			if err := flush(); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return report, err
+	}
+
+	log.Printf("Import finished: total=%d created=%d duplicates=%d failed=%d",
+		report.Total, report.Created, report.Duplicates, report.Failed)
+	return report, nil
+}
+
+// saveBatch отбрасывает песни, уже имеющиеся в базе, и сохраняет остальные в одной транзакции
+func (s *ImportService) saveBatch(ctx context.Context, batch []*models.Song, opts models.ImportOptions, meta models.AuditMeta, report *models.ImportReport) error {
+	groups := make([]string, len(batch))
+	names := make([]string, len(batch))
+	for i, song := range batch {
+		key := songKey(song.Group, song.SongName)
+		groups[i], names[i] = key[0], key[1]
+	}
+
+	existing, err := s.repo.FindExistingKeys(ctx, groups, names)
+	if err != nil {
+		return fmt.Errorf("failed to check existing songs: %w", err)
+	}
+
+	var fresh []*models.Song
+	queued := 0
+	for _, song := range batch {
+		if existing[songKey(song.Group, song.SongName)] {
+			report.Duplicates++
+			continue
+		}
+		if song.EnrichmentStatus == models.EnrichmentPending {
+			queued++
+		}
+		fresh = append(fresh, song)
+	}
+
+	if len(fresh) == 0 || opts.DryRun {
+		report.Created += len(fresh)
+		return nil
+	}
+
+	if err := s.repo.CreateSongsBatch(ctx, fresh, meta); err != nil {
+		log.Printf("Error saving import batch: %v", err)
+		report.Failed += len(fresh)
+		addImportError(report, 0, fmt.Errorf("batch of %d songs rolled back: %w", len(fresh), err))
+		return nil
+	}
+
+	report.Created += len(fresh)
+	report.Enrichment += queued
+	return nil
+}
+
+// songKey ключ дедупликации песни
+func songKey(group, song string) [2]string {
+	return [2]string{
+		strings.ToLower(strings.TrimSpace(group)),
+		strings.ToLower(strings.TrimSpace(song)),
+	}
+}
+
+// addImportError фиксирует ошибку строки, ограничивая размер отчета
+func addImportError(report *models.ImportReport, row int, err error) {
+	if row > 0 {
+		report.Failed++
+	}
+	if len(report.Errors) < maxImportErrors {
+		report.Errors = append(report.Errors, models.ImportRowError{Row: row, Error: err.Error()})
+	}
+}
+
+// validateImportRow проверяет строку импорта и преобразует ее в песню
+func validateImportRow(row models.ImportRow) (*models.Song, error) {
+	song := &models.Song{
+		Group:    row.Group,
+		SongName: row.Song,
+		Text:     row.Text,
+		Link:     row.Link,
+	}
+
+	date, dateErr := NormalizeReleaseDate(sanitizeLine(row.ReleaseDate))
+	song.ReleaseDate = date
+
+	err := NormalizeSong(song)
+	if dateErr != nil {
+		// Ошибка даты дополняет ошибки остальных полей
+		fields := []models.FieldError{*dateErr}
+		var domainErr *models.DomainError
+		if errors.As(err, &domainErr) {
+			fields = append(domainErr.Fields, fields...)
+		}
+		return nil, models.InvalidFields(fields...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return song, nil
+}
+
+// rowParseError ошибка разбора отдельной строки, не прерывающая импорт
+type rowParseError struct {
+	err error
+}
+
+func (e *rowParseError) Error() string { return e.err.Error() }
+
+func (e *rowParseError) Unwrap() error { return e.err }
+
+// malformedImportFile превращает ошибку разбора всего файла в ошибку валидации поля file;
+// ошибки чтения и отмена запроса возвращаются как есть
+func malformedImportFile(err error) error {
+	var csvErr *csv.ParseError
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &csvErr) || errors.As(err, &syntaxErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, bufio.ErrTooLong) {
+		return models.InvalidField("file", "is malformed: %v", err)
+	}
+	return err
+}
+
+// newRowReader возвращает функцию последовательного чтения строк в заданном формате
+func newRowReader(r io.Reader, format string) (func() (models.ImportRow, error), error) {
+	switch format {
+	case ImportFormatCSV:
+		return newCSVRowReader(r)
+	case ImportFormatJSON:
+		return newJSONRowReader(r)
+	case ImportFormatNDJSON:
+		return newNDJSONRowReader(r), nil
+	}
+	return nil, models.Validation("unsupported import format %q", format)
+}
+
+// newCSVRowReader читает CSV с заголовком group,song[,release_date,text,link]
+func newCSVRowReader(r io.Reader) (func() (models.ImportRow, error), error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, models.InvalidField("file", "must start with a CSV header")
+	}
+	if err != nil {
+		if err := malformedImportFile(err); errors.Is(err, models.ErrValidation) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if name == "date" {
+			name = "release_date"
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"group", "song"} {
+		if _, ok := columns[name]; !ok {
+			return nil, models.InvalidField("file", "CSV header must contain %s column", name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	return func() (models.ImportRow, error) {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return models.ImportRow{}, io.EOF
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return models.ImportRow{}, &rowParseError{err: err}
+			}
+			return models.ImportRow{}, err
+		}
+
+		return models.ImportRow{
+			Group:       field(record, "group"),
+			Song:        field(record, "song"),
+			ReleaseDate: field(record, "release_date"),
+			Text:        field(record, "text"),
+			Link:        field(record, "link"),
+		}, nil
+	}, nil
+}
+
+// newJSONRowReader потоково читает JSON-массив объектов
+func newJSONRowReader(r io.Reader) (func() (models.ImportRow, error), error) {
+	decoder := json.NewDecoder(r)
+
+	token, err := decoder.Token()
+	if err == io.EOF {
+		return nil, models.InvalidField("file", "must be a JSON array of songs")
+	}
+	if err != nil {
+		if err := malformedImportFile(err); errors.Is(err, models.ErrValidation) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read JSON: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, models.InvalidField("file", "must be a JSON array of songs")
+	}
+
+	return func() (models.ImportRow, error) {
+		if !decoder.More() {
+			return models.ImportRow{}, io.EOF
+		}
+
+		var row models.ImportRow
+		if err := decoder.Decode(&row); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				return row, &rowParseError{err: err}
+			}
+			return row, malformedImportFile(err)
+		}
+		return row, nil
+	}, nil
+}
+
+// newNDJSONRowReader читает по одному JSON-объекту на строку
+func newNDJSONRowReader(r io.Reader) func() (models.ImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	return func() (models.ImportRow, error) {
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			var row models.ImportRow
+			if err := json.Unmarshal([]byte(line), &row); err != nil {
+				return row, &rowParseError{err: err}
+			}
+			return row, nil
+		}
+
+		if err := scanner.Err(); err != nil {
+			return models.ImportRow{}, malformedImportFile(err)
+		}
+		return models.ImportRow{}, io.EOF
+	}
+}
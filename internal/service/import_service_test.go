@@ -0,0 +1,89 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"music-library/internal/models"
+)
+
+// readAllRows читает строки до конца файла или первой ошибки, не относящейся к строке
+func readAllRows(r io.Reader, format string) error {
+	next, err := newRowReader(r, format)
+	if err != nil {
+		return err
+	}
+	for {
+		_, err := next()
+		var rowErr *rowParseError
+		switch {
+		case err == io.EOF:
+			return nil
+		case errors.As(err, &rowErr):
+			continue
+		case err != nil:
+			return err
+		}
+	}
+}
+
+func TestRowReaderMalformedFileIsValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		input  string
+	}{
+		{"empty CSV", ImportFormatCSV, ""},
+		{"CSV without group", ImportFormatCSV, "song,text\nHello,World\n"},
+		{"CSV without song", ImportFormatCSV, "group,text\nMuse,World\n"},
+		{"CSV bad header quote", ImportFormatCSV, "\"group,song\n"},
+		{"empty JSON", ImportFormatJSON, ""},
+		{"JSON object", ImportFormatJSON, `{"group": "Muse"}`},
+		{"JSON syntax", ImportFormatJSON, `{"group" "Muse"}`},
+		{"JSON truncated array", ImportFormatJSON, `[{"group": "Muse", "song": "Uprising"`},
+		{"unknown format", "xml", "<songs/>"},
+	}
+
+	for _, tt := range tests {
+		err := readAllRows(strings.NewReader(tt.input), tt.format)
+		if !errors.Is(err, models.ErrValidation) {
+			t.Errorf("%s: err = %v, want validation error", tt.name, err)
+		}
+	}
+}
+
+func TestRowReaderIOErrorIsNotValidation(t *testing.T) {
+	failure := errors.New("connection reset")
+
+	for _, format := range []string{ImportFormatCSV, ImportFormatJSON, ImportFormatNDJSON} {
+		r := io.MultiReader(strings.NewReader("group,song\n"), iotest.ErrReader(failure))
+		if format != ImportFormatCSV {
+			r = iotest.ErrReader(failure)
+		}
+
+		err := readAllRows(r, format)
+		if !errors.Is(err, failure) || errors.Is(err, models.ErrValidation) {
+			t.Errorf("%s: err = %v, want unwrapped I/O error", format, err)
+		}
+	}
+}
+
+func TestRowReaderValidFiles(t *testing.T) {
+	tests := []struct {
+		format string
+		input  string
+	}{
+		{ImportFormatCSV, "\ufeffGroup,Song,Date\nMuse,Uprising,2009\n"},
+		{ImportFormatJSON, `[{"group": "Muse", "song": "Uprising"}, {"group": 5}]`},
+		{ImportFormatNDJSON, "{\"group\": \"Muse\", \"song\": \"Uprising\"}\n\nnot json\n"},
+	}
+
+	for _, tt := range tests {
+		if err := readAllRows(strings.NewReader(tt.input), tt.format); err != nil {
+			t.Errorf("%s: %v", tt.format, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter ограничивает частоту запросов алгоритмом маркерной корзины
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    int
+	tokens   float64
+	last     time.Time
+}
+
+// NewRateLimiter создает ограничитель на perSecond запросов в секунду с запасом burst;
+// perSecond <= 0 отключает ограничение
+func NewRateLimiter(perSecond float64, burst int) *RateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+
+	burst = max(burst, 1)
+	return &RateLimiter{
+		interval: time.Duration(float64(time.Second) / perSecond),
+		burst:    burst,
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// Wait ждет свободный маркер или отмену контекста
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	delay := l.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve забирает маркер и возвращает время ожидания до его появления
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens * float64(l.interval))
+}
+
+// cancel возвращает маркер, который так и не был использован
+func (l *RateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+}
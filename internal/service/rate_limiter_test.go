@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurst(t *testing.T) {
+	l := NewRateLimiter(10, 3)
+
+	for i := 0; i < 3; i++ {
+		if delay := l.reserve(); delay != 0 {
+			t.Fatalf("reserve %d: delay = %s, want 0 within burst", i+1, delay)
+		}
+	}
+
+	delay := l.reserve()
+	if delay <= 0 || delay > 100*time.Millisecond {
+		t.Fatalf("reserve after burst: delay = %s, want (0, 100ms]", delay)
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	l := NewRateLimiter(10, 2)
+	l.reserve()
+	l.reserve()
+
+	// Прошло 150ms: при 10 запросах в секунду накопилось полтора маркера
+	l.last = l.last.Add(-150 * time.Millisecond)
+	if delay := l.reserve(); delay != 0 {
+		t.Fatalf("reserve after refill: delay = %s, want 0", delay)
+	}
+	if delay := l.reserve(); delay <= 0 {
+		t.Fatalf("second reserve after partial refill: delay = %s, want > 0", delay)
+	}
+
+	// Долгий простой не дает накопить больше burst маркеров
+	l = NewRateLimiter(10, 2)
+	l.last = l.last.Add(-time.Hour)
+	l.reserve()
+	l.reserve()
+	if delay := l.reserve(); delay <= 0 {
+		t.Fatalf("reserve beyond burst after idle: delay = %s, want > 0", delay)
+	}
+}
+
+func TestRateLimiterCancelReturnsToken(t *testing.T) {
+	l := NewRateLimiter(1, 1)
+	l.reserve()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); err != context.Canceled {
+		t.Fatalf("Wait with canceled context: err = %v, want %v", err, context.Canceled)
+	}
+
+	// Отмененное ожидание вернуло маркер: следующий запрос ждет около секунды, а не двух
+	delay := l.reserve()
+	if delay <= 0 || delay > time.Second {
+		t.Fatalf("reserve after cancel: delay = %s, want (0, 1s]", delay)
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	l := NewRateLimiter(0, 5)
+	if l != nil {
+		t.Fatalf("NewRateLimiter(0, 5) = %v, want nil", l)
+	}
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("nil limiter Wait: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"log"
+	"music-library/internal/config"
+	"music-library/internal/repository"
+	"sync"
+	"time"
+)
+
+// RefreshScheduler периодически ставит в очередь обогащения устаревшие и неполные песни,
+// ограничивая число песен, добавляемых в минуту
+type RefreshScheduler struct {
+	repo            *repository.EnrichmentRepository
+	interval        time.Duration
+	staleAfter      time.Duration
+	incompleteAfter time.Duration
+	perTick         int
+	overwrite       bool
+	wg              sync.WaitGroup
+}
+
+// NewRefreshScheduler создает планировщик обновления метаданных
+func NewRefreshScheduler(repo *repository.EnrichmentRepository, cfg *config.Config) *RefreshScheduler {
+	interval := time.Duration(cfg.RefreshInterval) * time.Second
+
+	// Лимит в минуту пересчитывается в размер пачки на один запуск
+	perTick := int(float64(cfg.RefreshRate) * interval.Minutes())
+
+	return &RefreshScheduler{
+		repo:            repo,
+		interval:        interval,
+		staleAfter:      time.Duration(cfg.RefreshStaleAfter) * time.Hour,
+		incompleteAfter: time.Duration(cfg.RefreshIncompleteAfter) * time.Hour,
+		perTick:         max(perTick, 1),
+		overwrite:       cfg.RefreshOverwrite,
+	}
+}
+
+// Start запускает планировщик, который работает до отмены ctx; нулевой интервал отключает его
+func (s *RefreshScheduler) Start(ctx context.Context) {
+	if s.interval <= 0 {
+		log.Println("Metadata refresh is disabled")
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.refresh(ctx)
+			}
+		}
+	}()
+
+	log.Printf("Started metadata refresh every %s, up to %d songs per run", s.interval, s.perTick)
+}
+
+// Wait ожидает остановки планировщика
+func (s *RefreshScheduler) Wait() {
+	s.wg.Wait()
+}
+
+// refresh ставит в очередь очередную пачку устаревших песен
+func (s *RefreshScheduler) refresh(ctx context.Context) {
+	count, err := s.repo.EnqueueStaleSongs(ctx, s.staleAfter, s.incompleteAfter, s.overwrite, s.perTick)
+	if err != nil {
+		log.Printf("Error refreshing stale songs: %v", err)
+		return
+	}
+
+	if count > 0 {
+		log.Printf("Enqueued %d stale or incomplete songs for enrichment", count)
+	}
+}
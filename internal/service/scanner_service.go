@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dhowden/tag"
+)
+
+// scanExtensions расширения аудиофайлов, теги которых умеет читать сканер
+var scanExtensions = map[string]bool{
+	".mp3":  true,
+	".flac": true,
+	".ogg":  true,
+	".oga":  true,
+	".opus": true,
+}
+
+const maxScanErrors = 1000
+
+// ScannerService импортирует песни из тегов аудиофайлов локальной папки
+type ScannerService struct {
+	songService *SongService
+	repo        *repository.ScanRepository
+}
+
+// NewScannerService создает новый экземпляр сервиса сканирования
+func NewScannerService(songService *SongService, repo *repository.ScanRepository) *ScannerService {
+	return &ScannerService{
+		songService: songService,
+		repo:        repo,
+	}
+}
+
+// Scan обходит каталог и создает или обновляет песни по тегам измененных с прошлого раза файлов
+func (s *ScannerService) Scan(ctx context.Context, root string, opts models.ScanOptions, meta models.AuditMeta) (*models.ScanReport, error) {
+	meta.Source = models.SourceScanner
+
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scan directory: %w", err)
+	}
+
+	known, err := s.repo.GetScannedFiles(ctx, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scan state: %w", err)
+	}
+
+	report := &models.ScanReport{Errors: []models.ScanFileError{}}
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+		// Отмена прерывает обход; уже обработанные файлы остаются в отчете
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if walkErr != nil {
+			addScanError(report, path, walkErr)
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !scanExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		report.Files++
+
+		info, err := d.Info()
+		if err != nil {
+			addScanError(report, path, err)
+			return nil
+		}
+
+		// Файл не менялся с прошлого сканирования
+		prev, seen := known[path]
+		if !opts.Full && seen && prev.SongID > 0 &&
+			prev.ModTime.Equal(info.ModTime().Truncate(time.Microsecond)) && prev.Size == info.Size() {
+			report.Unchanged++
+			return nil
+		}
+
+		if err := s.scanFile(ctx, path, info, prev, opts, meta, report); err != nil {
+			addScanError(report, path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("failed to walk scan directory: %w", err)
+	}
+
+	log.Printf("Scan of %s finished: files=%d unchanged=%d created=%d updated=%d failed=%d",
+		root, report.Files, report.Unchanged, report.Created, report.Updated, report.Failed)
+	return report, nil
+}
+
+// scanFile читает теги файла и сохраняет по ним песню
+func (s *ScannerService) scanFile(ctx context.Context, path string, info fs.FileInfo, prev models.ScannedFile, opts models.ScanOptions, meta models.AuditMeta, report *models.ScanReport) error {
+	tagged, err := readSongTags(path)
+	if err != nil {
+		return err
+	}
+
+	// Сначала ищем песню, связанную с файлом, затем по группе и названию
+	var existing *models.Song
+	if prev.SongID > 0 {
+		if song, err := s.songService.GetSong(ctx, prev.SongID); err == nil {
+			existing = song
+		}
+	}
+	if existing == nil {
+		existing, err = s.songService.FindSong(ctx, tagged.Group, tagged.SongName)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.DryRun {
+		if existing == nil {
+			report.Created++
+		} else {
+			report.Updated++
+		}
+		return nil
+	}
+
+	var songID int
+	if existing == nil {
+		created, err := s.songService.SaveSong(ctx, *tagged, meta)
+		if err != nil {
+			return err
+		}
+		songID = created.ID
+		report.Created++
+	} else {
+		merged := mergeTaggedSong(*existing, *tagged)
+		if err := s.songService.UpdateSong(ctx, existing.ID, merged, meta); err != nil {
+			return err
+		}
+		songID = existing.ID
+		report.Updated++
+	}
+
+	return s.repo.SaveScannedFile(ctx, models.ScannedFile{
+		Path:    path,
+		ModTime: info.ModTime(),
+		Size:    info.Size(),
+		SongID:  songID,
+	})
+}
+
+// mergeTaggedSong дополняет существующую песню непустыми значениями из тегов
+func mergeTaggedSong(existing, tagged models.Song) models.Song {
+	for _, field := range models.SongFields {
+		value := tagged.FieldValue(field)
+		if value != "" && !existing.FieldLocked(field) {
+			existing.SetFieldValue(field, value)
+		}
+	}
+	return existing
+}
+
+// readSongTags извлекает исполнителя, название, дату и текст из тегов ID3v2 или Vorbis comment
+func readSongTags(path string) (*models.Song, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	metadata, err := tag.ReadFrom(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tags: %w", err)
+	}
+
+	group := strings.TrimSpace(metadata.Artist())
+	if group == "" {
+		group = strings.TrimSpace(metadata.AlbumArtist())
+	}
+	title := strings.TrimSpace(metadata.Title())
+	if group == "" || title == "" {
+		return nil, fmt.Errorf("missing artist or title tag")
+	}
+
+	return &models.Song{
+		Group:       group,
+		SongName:    title,
+		ReleaseDate: tagReleaseDate(metadata),
+		Text:        strings.TrimSpace(metadata.Lyrics()),
+	}, nil
+}
+
+// tagReleaseDate возвращает дату релиза из TDRC, TYER/TDAT или DATE с той точностью,
+// с которой она записана в тегах
+func tagReleaseDate(metadata tag.Metadata) models.ReleaseDate {
+	raw := metadata.Raw()
+	rawString := func(key string) string {
+		if v, ok := raw[key].(string); ok {
+			return strings.TrimSpace(v)
+		}
+		return ""
+	}
+
+	var value string
+	switch metadata.Format() {
+	case tag.VORBIS:
+		value = rawString("date")
+	case tag.ID3v2_4:
+		value = rawString("TDRC")
+	case tag.ID3v2_3:
+		value = rawString("TYER")
+		// TDAT хранит день и месяц в виде DDMM
+		if tdat := rawString("TDAT"); len(value) == 4 && len(tdat) == 4 {
+			value = fmt.Sprintf("%s-%s-%s", value, tdat[2:], tdat[:2])
+		}
+	}
+
+	// Отметка времени в TDRC отбрасывается: ГГГГ-ММ-ДД, ГГГГ-ММ или ГГГГ
+	for _, length := range []int{len("2006-01-02"), len("2006-01"), len("2006")} {
+		if len(value) >= length {
+			if date, err := models.ParseReleaseDate(value[:length]); err == nil && !date.IsZero() {
+				return date
+			}
+		}
+	}
+
+	if year := metadata.Year(); year > 0 {
+		return models.NewReleaseDate(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC), models.PrecisionYear)
+	}
+	return models.ReleaseDate{}
+}
+
+// addScanError фиксирует ошибку обработки файла, ограничивая размер отчета
+func addScanError(report *models.ScanReport, path string, err error) {
+	report.Failed++
+	if len(report.Errors) < maxScanErrors {
+		report.Errors = append(report.Errors, models.ScanFileError{Path: path, Error: err.Error()})
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+)
+
+// normalizeTitle приводит название группы или песни к форме для сравнения:
+// нижний регистр, без уточнений в скобках, пунктуации и артикля "the" в начале
+func normalizeTitle(s string) string {
+	s = strings.ToLower(s)
+	s = strings.ReplaceAll(s, "&", " and ")
+
+	var b strings.Builder
+	depth := 0
+	for _, r := range s {
+		switch {
+		case r == '(' || r == '[':
+			depth++
+		case r == ')' || r == ']':
+			if depth > 0 {
+				depth--
+			}
+		case depth > 0:
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+
+	normalized := strings.Join(strings.Fields(b.String()), " ")
+	if normalized == "" {
+		// Название целиком в скобках: сравниваем по исходным буквам
+		normalized = strings.Join(strings.Fields(strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return ' '
+		}, s)), " ")
+	}
+
+	return strings.TrimPrefix(normalized, "the ")
+}
+
+// stringSimilarity возвращает сходство строк от 0 до 1 на основе расстояния Левенштейна
+func stringSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	maxLen := len(ra)
+	if len(rb) > maxLen {
+		maxLen = len(rb)
+	}
+	if maxLen == 0 {
+		return 1
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(maxLen)
+}
+
+// levenshtein вычисляет редакционное расстояние между строками
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+// lyricShingles разбивает текст песни на множество последовательностей из трех слов
+func lyricShingles(text string) map[string]struct{} {
+	words := strings.Fields(normalizeTitle(text))
+	if len(words) == 0 {
+		return nil
+	}
+
+	shingles := make(map[string]struct{})
+	if len(words) < 3 {
+		shingles[strings.Join(words, " ")] = struct{}{}
+		return shingles
+	}
+	for i := 0; i+3 <= len(words); i++ {
+		shingles[strings.Join(words[i:i+3], " ")] = struct{}{}
+	}
+
+	return shingles
+}
+
+// jaccard возвращает коэффициент Жаккара двух множеств
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	common := 0
+	for k := range a {
+		if _, ok := b[k]; ok {
+			common++
+		}
+	}
+
+	return float64(common) / float64(len(a)+len(b)-common)
+}
@@ -1,41 +1,56 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"music-library/internal/config"
 	"music-library/internal/models"
 	"music-library/internal/repository"
 	"strings"
-	"time"
 )
 
 // SongService представляет сервисный слой для работы с песнями
 type SongService struct {
-	repo        *repository.SongRepository
-	externalAPI *ExternalAPIService
+	repo       *repository.SongRepository
+	songsPage  pageLimits
+	versesPage pageLimits
 }
 
-// NewSongService создает новый экземпляр сервиса песен
-func NewSongService(repo *repository.SongRepository, externalAPI *ExternalAPIService) *SongService {
+// pageLimits размер страницы по умолчанию и наибольший допустимый
+type pageLimits struct {
+	def int
+	max int
+}
+
+// apply заменяет отсутствующий или недопустимый размер страницы значением по умолчанию
+func (l pageLimits) apply(limit int) int {
+	if limit < 1 || limit > l.max {
+		return l.def
+	}
+	return limit
+}
+
+// NewSongService создает новый экземпляр сервиса песен с размерами страниц из конфигурации
+func NewSongService(repo *repository.SongRepository, cfg *config.Config) *SongService {
 	return &SongService{
-		repo:        repo,
-		externalAPI: externalAPI,
+		repo:       repo,
+		songsPage:  pageLimits{def: cfg.SongsPageSize, max: cfg.SongsMaxPageSize},
+		versesPage: pageLimits{def: cfg.VersesPageSize, max: cfg.VersesMaxPageSize},
 	}
 }
 
 // GetSongs возвращает список песен с применением фильтрации и пагинации
-func (s *SongService) GetSongs(group string, page, limit int) ([]models.Song, error) {
+func (s *SongService) GetSongs(ctx context.Context, filter models.SongFilter, page, limit int) ([]models.Song, error) {
 	// Валидация входных параметров
 	if page < 1 {
 		page = 1
 	}
-	if limit < 1 || limit > 100 {
-		limit = 10 // значение по умолчанию
-	}
+	limit = s.songsPage.apply(limit)
 
-	log.Printf("Fetching songs for group: %s, page: %d, limit: %d", group, page, limit)
+	log.Printf("Fetching songs for group: %s, song: %s, page: %d, limit: %d", filter.Group, filter.SongName, page, limit)
 
-	songs, err := s.repo.GetSongs(group, page, limit)
+	songs, err := s.repo.GetSongs(ctx, filter, page, limit)
 	if err != nil {
 		log.Printf("Error in GetSongs: %v", err)
 		return nil, fmt.Errorf("failed to retrieve songs: %w", err)
@@ -44,39 +59,64 @@ func (s *SongService) GetSongs(group string, page, limit int) ([]models.Song, er
 	return songs, nil
 }
 
-// CreateSong создает новую песню с обогащением данными из внешнего API
-func (s *SongService) CreateSong(req models.CreateSongRequest) (*models.Song, error) {
-	// Нормализация входных данных
-	group := strings.TrimSpace(req.Group)
-	songName := strings.TrimSpace(req.Song)
+// CreateSong сохраняет новую песню сразу и ставит ее в очередь обогащения данными внешнего API
+func (s *SongService) CreateSong(ctx context.Context, req models.CreateSongRequest, meta models.AuditMeta) (*models.Song, error) {
+	// Дата, текст и ссылка будут заполнены воркером обогащения
+	song := &models.Song{
+		Group:            req.Group,
+		SongName:         req.Song,
+		EnrichmentStatus: models.EnrichmentPending,
+	}
 
-	if group == "" || songName == "" {
-		return nil, fmt.Errorf("group and song name cannot be empty")
+	// Нормализация и проверка входных данных
+	if err := NormalizeSong(song); err != nil {
+		return nil, err
 	}
 
-	// Получение дополнительной информации из внешнего API
-	songDetails, err := s.externalAPI.GetSongDetails(group, songName)
+	// Сохранение песни в репозитории
+	createdSong, err := s.repo.CreateSong(ctx, song, meta)
 	if err != nil {
-		log.Printf("Error fetching song details from external API: %v", err)
-		return nil, fmt.Errorf("failed to fetch song details: %w", err)
+		log.Printf("Error creating song in repository: %v", err)
+		return nil, fmt.Errorf("failed to create song: %w", err)
 	}
 
-	// Обработка даты релиза
-	if songDetails.ReleaseDate == "" {
-		songDetails.ReleaseDate = time.Now().Format("02.01.2006")
+	log.Printf("Created song: %s by %s", createdSong.SongName, createdSong.Group)
+	return createdSong, nil
+}
+
+// GetSong возвращает песню по идентификатору
+func (s *SongService) GetSong(ctx context.Context, songID int) (*models.Song, error) {
+	if songID <= 0 {
+		return nil, models.Validation("invalid song ID")
 	}
 
-	// Создание объекта песни для сохранения в базе данных
-	song := &models.Song{
-		Group:       group,
-		SongName:    songName,
-		ReleaseDate: songDetails.ReleaseDate,
-		Text:        songDetails.Text,
-		Link:        songDetails.Link,
+	song, err := s.repo.GetSongByID(ctx, songID)
+	if err != nil {
+		log.Printf("Error getting song: %v", err)
+		return nil, fmt.Errorf("failed to retrieve song: %w", err)
 	}
 
-	// Сохранение песни в репозитории
-	createdSong, err := s.repo.CreateSong(song)
+	return song, nil
+}
+
+// FindSong ищет песню по группе и названию, nil означает отсутствие
+func (s *SongService) FindSong(ctx context.Context, group, songName string) (*models.Song, error) {
+	song, err := s.repo.FindSongByKey(ctx, sanitizeLine(group), sanitizeLine(songName))
+	if err != nil {
+		log.Printf("Error finding song: %v", err)
+		return nil, fmt.Errorf("failed to find song: %w", err)
+	}
+
+	return song, nil
+}
+
+// SaveSong создает песню из уже известных данных без обращения к внешнему API
+func (s *SongService) SaveSong(ctx context.Context, song models.Song, meta models.AuditMeta) (*models.Song, error) {
+	if err := NormalizeSong(&song); err != nil {
+		return nil, err
+	}
+
+	createdSong, err := s.repo.CreateSong(ctx, &song, meta)
 	if err != nil {
 		log.Printf("Error creating song in repository: %v", err)
 		return nil, fmt.Errorf("failed to create song: %w", err)
@@ -87,29 +127,23 @@ func (s *SongService) CreateSong(req models.CreateSongRequest) (*models.Song, er
 }
 
 // UpdateSong обновляет информацию о песне
-func (s *SongService) UpdateSong(songID int, updateData models.Song) error {
+func (s *SongService) UpdateSong(ctx context.Context, songID int, updateData models.Song, meta models.AuditMeta) error {
 	// Валидация входных данных
 	if songID <= 0 {
-		return fmt.Errorf("invalid song ID")
+		return models.Validation("invalid song ID")
 	}
 
-	// Нормализация данных
+	// Нормализация данных; происхождение полей клиент не задает
 	updateData.ID = songID
-	updateData.Group = strings.TrimSpace(updateData.Group)
-	updateData.SongName = strings.TrimSpace(updateData.SongName)
+	updateData.Provenance = nil
 
-	// Проверка обязательных полей
-	if updateData.Group == "" || updateData.SongName == "" {
-		return fmt.Errorf("group and song name cannot be empty")
-	}
-
-	// Обновление даты релиза, если не указана
-	if updateData.ReleaseDate == "" {
-		updateData.ReleaseDate = time.Now().Format("02.01.2006")
+	// Проверка и приведение полей к каноническому виду
+	if err := NormalizeSong(&updateData); err != nil {
+		return err
 	}
 
 	// Вызов репозитория для обновления
-	err := s.repo.UpdateSong(&updateData)
+	err := s.repo.UpdateSong(ctx, &updateData, meta)
 	if err != nil {
 		log.Printf("Error updating song: %v", err)
 		return fmt.Errorf("failed to update song: %w", err)
@@ -120,14 +154,14 @@ func (s *SongService) UpdateSong(songID int, updateData models.Song) error {
 }
 
 // DeleteSong удаляет песню по идентификатору
-func (s *SongService) DeleteSong(songID int) error {
+func (s *SongService) DeleteSong(ctx context.Context, songID int, meta models.AuditMeta) error {
 	// Валидация входных данных
 	if songID <= 0 {
-		return fmt.Errorf("invalid song ID")
+		return models.Validation("invalid song ID")
 	}
 
 	// Вызов репозитория для удаления
-	err := s.repo.DeleteSong(songID)
+	err := s.repo.DeleteSong(ctx, songID, meta)
 	if err != nil {
 		log.Printf("Error deleting song: %v", err)
 		return fmt.Errorf("failed to delete song: %w", err)
@@ -137,23 +171,50 @@ func (s *SongService) DeleteSong(songID int) error {
 	return nil
 }
 
+// SetFieldLocks блокирует или разблокирует поля песни от перезаписи обогащением
+func (s *SongService) SetFieldLocks(ctx context.Context, songID int, req models.FieldLocksRequest, meta models.AuditMeta) (*models.Song, error) {
+	if songID <= 0 {
+		return nil, models.Validation("invalid song ID")
+	}
+
+	fields := make([]string, 0, len(req.Fields))
+	seen := make(map[string]bool)
+	for _, field := range req.Fields {
+		field = strings.TrimSpace(field)
+		if !models.IsSongField(field) {
+			return nil, models.Validation("unknown song field %q", field)
+		}
+		if !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+
+	song, err := s.repo.SetFieldLocks(ctx, songID, fields, req.Locked, meta)
+	if err != nil {
+		log.Printf("Error updating field locks: %v", err)
+		return nil, fmt.Errorf("failed to update field locks: %w", err)
+	}
+
+	log.Printf("Updated field locks of song ID %d: %v locked=%t", songID, fields, req.Locked)
+	return song, nil
+}
+
 // GetSongText возвращает текст песни постранично
-func (s *SongService) GetSongText(songID, page, limit int) (string, error) {
+func (s *SongService) GetSongText(ctx context.Context, songID, page, limit int) (string, error) {
 	// Валидация входных параметров
 	if songID <= 0 {
-		return "", fmt.Errorf("invalid song ID")
+		return "", models.Validation("invalid song ID")
 	}
 
 	if page < 1 {
 		page = 1
 	}
 
-	if limit < 1 || limit > 10 {
-		limit = 3 // значение по умолчанию
-	}
+	limit = s.versesPage.apply(limit)
 
 	// Получение текста песни постранично
-	songText, err := s.repo.GetSongText(songID, page, limit)
+	songText, err := s.repo.GetSongText(ctx, songID, page, limit)
 	if err != nil {
 		log.Printf("Error getting song text: %v", err)
 		return "", fmt.Errorf("failed to retrieve song text: %w", err)
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"fmt"
+	"music-library/internal/models"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Ограничения длины полей песни в символах, совпадающие со схемой таблицы songs
+const (
+	MaxGroupLength = 255
+	MaxSongLength  = 255
+	MaxLinkLength  = 512
+)
+
+// NormalizeSong приводит поля песни к каноническому виду и проверяет их. Все нарушения
+// собираются в одну ошибку валидации с перечнем полей
+func NormalizeSong(song *models.Song) error {
+	var fields []models.FieldError
+
+	song.Group = sanitizeLine(song.Group)
+	song.SongName = sanitizeLine(song.SongName)
+	song.Text = sanitizeText(song.Text)
+	song.Link = sanitizeLine(song.Link)
+
+	fields = appendRequired(fields, models.FieldGroup, song.Group, MaxGroupLength)
+	fields = appendRequired(fields, models.FieldSong, song.SongName, MaxSongLength)
+
+	if song.ReleaseDate.Err() != nil {
+		fields = append(fields, models.NewFieldError(models.FieldReleaseDate, models.ReleaseDateFormatMessage))
+	}
+
+	if song.Link != "" {
+		if err := validateSongLink(song.Link); err != nil {
+			fields = append(fields, *err)
+		}
+	}
+
+	if len(fields) > 0 {
+		return models.InvalidFields(fields...)
+	}
+	return nil
+}
+
+// normalizeSongField приводит к каноническому виду одно поле, пришедшее из источника
+// обогащения; некорректное значение возвращается ошибкой
+func normalizeSongField(field, value string) (string, error) {
+	var fieldErr *models.FieldError
+
+	switch field {
+	case models.FieldText:
+		return sanitizeText(value), nil
+	case models.FieldReleaseDate:
+		var date models.ReleaseDate
+		date, fieldErr = NormalizeReleaseDate(sanitizeLine(value))
+		value = date.String()
+	case models.FieldLink:
+		value = sanitizeLine(value)
+		fieldErr = validateSongLink(value)
+	default:
+		value = sanitizeLine(value)
+	}
+
+	if fieldErr != nil {
+		return "", fmt.Errorf("%s %s", fieldErr.Field, fieldErr.Message)
+	}
+	return value, nil
+}
+
+// appendRequired проверяет обязательное поле с ограничением длины
+func appendRequired(fields []models.FieldError, field, value string, maxLength int) []models.FieldError {
+	if value == "" {
+		return append(fields, models.NewFieldError(field, "is required"))
+	}
+	if utf8.RuneCountInString(value) > maxLength {
+		return append(fields, models.NewFieldError(field, "must be at most %d characters", maxLength))
+	}
+	return fields
+}
+
+// NormalizeReleaseDate разбирает дату релиза из строки и сохраняет ее точность
+func NormalizeReleaseDate(value string) (models.ReleaseDate, *models.FieldError) {
+	date, err := models.ParseReleaseDate(value)
+	if err != nil {
+		fieldErr := models.NewFieldError(models.FieldReleaseDate, models.ReleaseDateFormatMessage)
+		return models.ReleaseDate{}, &fieldErr
+	}
+	return date, nil
+}
+
+// validateSongLink допускает только абсолютные ссылки http и https в пределах длины столбца
+func validateSongLink(value string) *models.FieldError {
+	var err models.FieldError
+	if utf8.RuneCountInString(value) > MaxLinkLength {
+		err = models.NewFieldError(models.FieldLink, "must be at most %d characters", MaxLinkLength)
+		return &err
+	}
+
+	u, parseErr := url.Parse(value)
+	if parseErr != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		err = models.NewFieldError(models.FieldLink, "must be an absolute http(s) URL")
+		return &err
+	}
+	return nil
+}
+
+// sanitizeLine нормализует однострочное значение: NFC, без управляющих символов,
+// переводы строк и табуляции заменяются пробелом
+func sanitizeLine(value string) string {
+	value = norm.NFC.String(value)
+	value = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\r' || r == '\t':
+			return ' '
+		case unicode.IsControl(r) || r == '\ufeff':
+			return -1
+		}
+		return r
+	}, value)
+	return strings.TrimSpace(value)
+}
+
+// sanitizeText нормализует многострочный текст: NFC, переводы строк LF, без управляющих
+// символов кроме перевода строки и табуляции
+func sanitizeText(value string) string {
+	value = norm.NFC.String(value)
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	value = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\r':
+			return '\n'
+		case r == '\n' || r == '\t':
+			return r
+		case unicode.IsControl(r) || r == '\ufeff':
+			return -1
+		}
+		return r
+	}, value)
+	return strings.TrimSpace(value)
+}
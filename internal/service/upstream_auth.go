@@ -0,0 +1,90 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"music-library/internal/config"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Способы аутентификации во внешнем API
+const (
+	AuthNone   = "none"
+	AuthHeader = "header"
+	AuthBearer = "bearer"
+	AuthHMAC   = "hmac"
+)
+
+// Заголовки подписанного запроса
+const (
+	SignatureTimestampHeader = "X-Timestamp"
+	SignatureHeader          = "X-Signature"
+)
+
+// requestAuthenticator добавляет к запросу учетные данные внешнего API
+type requestAuthenticator struct {
+	mode   string
+	header string
+	key    string
+	secret []byte
+}
+
+// newRequestAuthenticator проверяет параметры аутентификации из конфигурации
+func newRequestAuthenticator(cfg *config.Config) (*requestAuthenticator, error) {
+	auth := &requestAuthenticator{
+		mode:   cfg.APIAuthMode,
+		header: cfg.APIAuthHeader,
+		key:    cfg.APIKey,
+		secret: []byte(cfg.APIHMACSecret),
+	}
+
+	switch auth.mode {
+	case "", AuthNone:
+		auth.mode = AuthNone
+	case AuthHeader:
+		if auth.header == "" || auth.key == "" {
+			return nil, fmt.Errorf("API_AUTH_HEADER and API_KEY are required for header authentication")
+		}
+	case AuthBearer:
+		if auth.key == "" {
+			return nil, fmt.Errorf("API_KEY is required for bearer authentication")
+		}
+	case AuthHMAC:
+		if len(auth.secret) == 0 {
+			return nil, fmt.Errorf("API_HMAC_SECRET is required for HMAC authentication")
+		}
+	default:
+		return nil, fmt.Errorf("unknown API auth mode %q", auth.mode)
+	}
+
+	return auth, nil
+}
+
+// apply подписывает или дополняет запрос учетными данными
+func (a *requestAuthenticator) apply(req *http.Request) {
+	switch a.mode {
+	case AuthHeader:
+		req.Header.Set(a.header, a.key)
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+a.key)
+	case AuthHMAC:
+		// Идентификатор ключа передается вместе с подписью, если он задан
+		if a.key != "" && a.header != "" {
+			req.Header.Set(a.header, a.key)
+		}
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(SignatureTimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, a.sign(req.Method, req.URL.RequestURI(), timestamp))
+	}
+}
+
+// sign вычисляет HMAC-SHA256 от метода, пути с параметрами и времени запроса
+func (a *requestAuthenticator) sign(method, uri, timestamp string) string {
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(method + "\n" + uri + "\n" + timestamp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
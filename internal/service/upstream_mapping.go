@@ -0,0 +1,198 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"music-library/internal/config"
+	"music-library/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// upstreamSongDetails данные песни в том виде, в каком их вернул внешний API,
+// до разбора даты и проверки значений
+type upstreamSongDetails struct {
+	ReleaseDate string
+	Text        string
+	Link        string
+}
+
+// ResponseMapping описывает, где в ответе внешнего API лежат поля песни и как их разбирать.
+// Путь — ключи через точку с индексами массивов (data.items.0.text); для поля можно
+// перечислить несколько путей через запятую, используется первый найденный
+type ResponseMapping struct {
+	ReleaseDate []string
+	Text        []string
+	Link        []string
+	DateFormats []string
+	// Strict отклоняет весь ответ при некорректном поле; иначе поле отбрасывается с записью в лог
+	Strict bool
+}
+
+// NewResponseMappingFromConfig собирает правила разбора ответа из конфигурации
+func NewResponseMappingFromConfig(cfg *config.Config) *ResponseMapping {
+	return &ResponseMapping{
+		ReleaseDate: splitList(cfg.APIFieldReleaseDate),
+		Text:        splitList(cfg.APIFieldText),
+		Link:        splitList(cfg.APIFieldLink),
+		DateFormats: splitList(cfg.APIDateFormats),
+		Strict:      cfg.APIStrictResponse,
+	}
+}
+
+// splitList разбирает список значений через запятую, пропуская пустые
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Map разбирает тело ответа в данные песни; ответ, в котором не нашлось ни одного
+// из настроенных полей, считается несоответствием схемы и отклоняется
+func (m *ResponseMapping) Map(body []byte) (*models.Song, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	if _, ok := doc.(map[string]any); !ok {
+		return nil, fmt.Errorf("%w: expected JSON object", ErrInvalidResponse)
+	}
+
+	var raw upstreamSongDetails
+	found := false
+	for _, f := range []struct {
+		name  string
+		paths []string
+		dst   *string
+	}{
+		{models.FieldReleaseDate, m.ReleaseDate, &raw.ReleaseDate},
+		{models.FieldText, m.Text, &raw.Text},
+		{models.FieldLink, m.Link, &raw.Link},
+	} {
+		value, ok, err := lookupFirst(doc, f.paths)
+		if err != nil {
+			if err := m.flag(f.name, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if ok {
+			found = true
+			*f.dst = value
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("%w: none of the mapped fields are present", ErrInvalidResponse)
+	}
+
+	return m.validate(raw)
+}
+
+// validate проверяет значения и разбирает дату релиза
+func (m *ResponseMapping) validate(raw upstreamSongDetails) (*models.Song, error) {
+	details := &models.Song{Text: raw.Text}
+
+	if raw.ReleaseDate != "" {
+		date, err := m.parseDate(raw.ReleaseDate)
+		if err != nil {
+			if err := m.flag(models.FieldReleaseDate, err); err != nil {
+				return nil, err
+			}
+		} else {
+			details.ReleaseDate = date
+		}
+	}
+
+	if raw.Link != "" {
+		// Ссылка проверяется по тем же правилам, что и ссылка, введенная вручную
+		link, err := normalizeSongField(models.FieldLink, raw.Link)
+		if err != nil {
+			if err := m.flag(models.FieldLink, err); err != nil {
+				return nil, err
+			}
+		} else {
+			details.Link = link
+		}
+	}
+
+	return details, nil
+}
+
+// flag в строгом режиме превращает ошибку поля в отказ, иначе только пишет ее в лог
+func (m *ResponseMapping) flag(field string, err error) error {
+	if m.Strict {
+		return fmt.Errorf("%w: field %s: %v", ErrInvalidResponse, field, err)
+	}
+	log.Printf("Dropping malformed %s from external API response: %v", field, err)
+	return nil
+}
+
+// parseDate разбирает дату в одном из настроенных форматов с точностью, которую задает
+// сам формат (для "2006" — год); иначе пробует общие форматы, включая неполные даты
+func (m *ResponseMapping) parseDate(value string) (models.ReleaseDate, error) {
+	for _, layout := range m.DateFormats {
+		if t, err := time.Parse(layout, value); err == nil {
+			return models.NewReleaseDate(t, models.LayoutPrecision(layout)), nil
+		}
+	}
+	if date, err := models.ParseReleaseDate(value); err == nil {
+		return date, nil
+	}
+	return models.ReleaseDate{}, fmt.Errorf("unsupported date %q", value)
+}
+
+// lookupFirst возвращает значение по первому существующему пути
+func lookupFirst(doc any, paths []string) (string, bool, error) {
+	for _, path := range paths {
+		value, ok := lookupJSONPath(doc, path)
+		if !ok || value == nil {
+			continue
+		}
+
+		switch v := value.(type) {
+		case string:
+			return strings.TrimSpace(v), true, nil
+		case json.Number:
+			// Например, год релиза, переданный числом
+			return v.String(), true, nil
+		default:
+			return "", false, fmt.Errorf("path %s: expected string, got %T", path, value)
+		}
+	}
+	return "", false, nil
+}
+
+// lookupJSONPath спускается по ключам объектов и индексам массивов
+func lookupJSONPath(doc any, path string) (any, bool) {
+	current := doc
+	for _, part := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[part]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []any:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"music-library/internal/models"
+)
+
+func TestResponseMappingParseDatePrecision(t *testing.T) {
+	m := &ResponseMapping{DateFormats: []string{"2006", "01.2006", "02.01.2006", "2006/01/02"}}
+
+	tests := []struct {
+		value     string
+		want      string
+		precision models.DatePrecision
+	}{
+		{"1997", "1997", models.PrecisionYear},
+		{"07.2006", "2006-07", models.PrecisionMonth},
+		{"16.07.2006", "2006-07-16", models.PrecisionDay},
+		{"2006/07/16", "2006-07-16", models.PrecisionDay},
+		// Не подошел ни один настроенный формат: используются общие
+		{"2006-07", "2006-07", models.PrecisionMonth},
+	}
+
+	for _, tt := range tests {
+		d, err := m.parseDate(tt.value)
+		if err != nil {
+			t.Fatalf("parseDate(%q): %v", tt.value, err)
+		}
+		if d.String() != tt.want || d.Precision() != tt.precision {
+			t.Errorf("parseDate(%q) = %q (%s), want %q (%s)", tt.value, d.String(), d.Precision(), tt.want, tt.precision)
+		}
+	}
+
+	if _, err := m.parseDate("someday"); err == nil {
+		t.Error("parseDate(\"someday\"): want error")
+	}
+}
+
+func TestResponseMappingLinkRules(t *testing.T) {
+	long := "https://example.com/" + strings.Repeat("a", MaxLinkLength)
+
+	tests := []struct {
+		link    string
+		want    string
+		invalid bool
+	}{
+		{"https://example.com/song", "https://example.com/song", false},
+		{" https://example.com/song\n", "https://example.com/song", false},
+		{"ftp://example.com/song", "", true},
+		{"/song", "", true},
+		{long, "", true},
+	}
+
+	for _, tt := range tests {
+		body := fmt.Sprintf(`{"text": "Ooh baby", "link": %q}`, tt.link)
+
+		lenient := &ResponseMapping{Text: []string{"text"}, Link: []string{"link"}}
+		details, err := lenient.Map([]byte(body))
+		if err != nil {
+			t.Fatalf("Map(%q): %v", tt.link, err)
+		}
+		if details.Link != tt.want {
+			t.Errorf("Map(%q): link = %q, want %q", tt.link, details.Link, tt.want)
+		}
+
+		strict := &ResponseMapping{Text: []string{"text"}, Link: []string{"link"}, Strict: true}
+		if _, err := strict.Map([]byte(body)); (err != nil) != tt.invalid {
+			t.Errorf("strict Map(%q): err = %v, want error %t", tt.link, err, tt.invalid)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    action VARCHAR(32) NOT NULL,
+    song_id INTEGER,
+    actor VARCHAR(255) NOT NULL,
+    ip VARCHAR(64),
+    method VARCHAR(16),
+    route VARCHAR(255),
+    request_id VARCHAR(64),
+    before JSONB,
+    after JSONB
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_song_id ON audit_log (song_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor);
+
+-- Журнал только дополняется: изменение и удаление записей запрещены
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_no_modify ON audit_log;
+CREATE TRIGGER audit_log_no_modify
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS scanned_files (
+    path TEXT PRIMARY KEY,
+    mod_time TIMESTAMPTZ NOT NULL,
+    size BIGINT NOT NULL,
+    song_id INTEGER REFERENCES songs (id) ON DELETE SET NULL,
+    scanned_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scanned_files_song_id ON scanned_files (song_id);
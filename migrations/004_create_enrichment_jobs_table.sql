@@ -0,0 +1,22 @@
+ALTER TABLE songs
+    ADD COLUMN IF NOT EXISTS enrichment_status VARCHAR(16) NOT NULL DEFAULT 'done';
+
+CREATE TABLE IF NOT EXISTS enrichment_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    song_id INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL DEFAULT 'queued',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_run_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Для песни допускается не более одной активной задачи
+CREATE UNIQUE INDEX IF NOT EXISTS idx_enrichment_jobs_active_song
+    ON enrichment_jobs (song_id) WHERE status IN ('queued', 'running');
+
+CREATE INDEX IF NOT EXISTS idx_enrichment_jobs_next_run
+    ON enrichment_jobs (next_run_at) WHERE status = 'queued';
+CREATE INDEX IF NOT EXISTS idx_enrichment_jobs_status ON enrichment_jobs (status);
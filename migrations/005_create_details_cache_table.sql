@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS details_cache (
+    key TEXT PRIMARY KEY,
+    group_name VARCHAR(255) NOT NULL,
+    song_name VARCHAR(255) NOT NULL,
+    details JSONB,
+    not_found BOOLEAN NOT NULL DEFAULT false,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_details_cache_expires_at ON details_cache (expires_at);
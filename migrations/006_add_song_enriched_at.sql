@@ -0,0 +1,5 @@
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS enriched_at TIMESTAMPTZ;
+
+ALTER TABLE enrichment_jobs ADD COLUMN IF NOT EXISTS overwrite BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_songs_enriched_at ON songs (enriched_at NULLS FIRST);
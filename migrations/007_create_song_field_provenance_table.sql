@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS song_field_provenance (
+    song_id INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    field VARCHAR(32) NOT NULL,
+    source VARCHAR(64) NOT NULL,
+    fetched_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    locked BOOLEAN NOT NULL DEFAULT false,
+    PRIMARY KEY (song_id, field)
+);
@@ -0,0 +1,13 @@
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS release_date_precision VARCHAR(5);
+
+-- Существующие даты записаны с точностью до дня
+UPDATE songs SET release_date_precision = 'day'
+WHERE release_date IS NOT NULL AND release_date_precision IS NULL;
+
+ALTER TABLE songs DROP CONSTRAINT IF EXISTS songs_release_date_precision_check;
+ALTER TABLE songs ADD CONSTRAINT songs_release_date_precision_check CHECK (
+    (release_date IS NULL AND release_date_precision IS NULL) OR
+    (release_date IS NOT NULL AND release_date_precision IN ('year', 'month', 'day'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_songs_release_date ON songs (release_date);
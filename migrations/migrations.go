@@ -0,0 +1,10 @@
+// Package migrations содержит SQL-миграции схемы базы данных. Файлы встраиваются
+// в бинарный файл и применяются по порядку имен командой migrate
+package migrations
+
+import "embed"
+
+// Files файлы миграций; версия миграции — имя файла без расширения
+//
+//go:embed *.sql
+var Files embed.FS